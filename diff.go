@@ -0,0 +1,105 @@
+package main
+
+import (
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/compat"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+func runDiff(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: jsonschema diff [flags] old.json new.json")
+		fmt.Fprintln(stderr, "\nclassifies every change between two schemas as widening, narrowing or incompatible.")
+		fmt.Fprintln(stderr, "exits with 1 when a change can reject values that were accepted")
+		fs.PrintDefaults()
+	}
+	corpus := fs.String("corpus", "", "file or glob of ndjson listings to replay through both schemas")
+	coerce := fs.Bool("coerce", false, "coerce stringified values when replaying the corpus")
+	format := fs.String("format", "text", "output format, json or text")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return exitError
+	}
+	if *format != "json" && *format != "text" {
+		fmt.Fprintf(stderr, "unknown format <%s>, expected json or text\n", *format)
+		return exitError
+	}
+
+	oldSchema, err := loadSchema(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	newSchema, err := loadSchema(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	changes := compat.Diff(oldSchema, newSchema)
+
+	var results []compat.KeyResult
+	if *corpus != "" {
+		paths, err := expandInputs([]string{*corpus})
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		replayer := compat.NewReplayer(oldSchema, newSchema, *coerce, 3)
+		err = forEachListing(paths, stdin, func(l approach_3.Listing) error {
+			replayer.Add(l)
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		results = replayer.Results()
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "\t")
+		err = enc.Encode(struct {
+			Changes []compat.Change    `json:"changes"`
+			Corpus  []compat.KeyResult `json:"corpus,omitempty"`
+		}{changes, results})
+	} else {
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		for _, c := range changes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Kind, c.Path, c.Description)
+		}
+		if *corpus != "" {
+			fmt.Fprintln(tw, "\nKEY\tTOTAL\tNOW REJECTED\tNOW ACCEPTED\tSAMPLES")
+			for _, r := range results {
+				fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%v\n", r.Key, r.Total, r.NowRejected, r.NowAccepted, r.Samples)
+			}
+		}
+		err = tw.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	for _, c := range changes {
+		if c.Breaking() {
+			return exitInvalid
+		}
+	}
+	for _, r := range results {
+		if r.NowRejected > 0 {
+			return exitInvalid
+		}
+	}
+	return exitOK
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"cmenke/go-playground/lib/approach_3/codegen"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+func runGen(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: jsonschema gen [flags]")
+		fmt.Fprintln(stderr, "\ngenerates typed Go structs validating and coercing their values like the schema does")
+		fs.PrintDefaults()
+	}
+	schemaPath := fs.String("schema", "./schema.json", "path to the schema file")
+	pkg := fs.String("package", "listing", "package name of the generated file")
+	root := fs.String("root", "Listing", "name of the struct generated for the root schema")
+	outPath := fs.String("out", "-", "where to write the generated file, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitError
+	}
+
+	s, err := loadSchema(*schemaPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	src, err := codegen.Generate(s, codegen.Options{Package: *pkg, Root: *root, Source: filepath.Base(*schemaPath)})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	out, closeOut, err := openOutput(*outPath, stdout)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	defer closeOut()
+	if _, err := out.Write(src); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/infer"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+func runInfer(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("infer", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: jsonschema infer [flags] [file|glob|-]...")
+		fmt.Fprintln(stderr, "\nproposes a schema from a corpus of ndjson listings")
+		fs.PrintDefaults()
+	}
+	maxEnum := fs.Int("max-enum", infer.DefaultOptions.MaxEnum, "highest number of distinct strings proposed as an enum, 0 disables enums")
+	minEnumSamples := fs.Int("min-enum-samples", infer.DefaultOptions.MinEnumSamples, "lowest number of values seen before proposing an enum")
+	statsPath := fs.String("stats", "", "where to write the confidence of every inferred constraint, - for stderr")
+	statsFormat := fs.String("stats-format", "text", "confidence statistics format, json or text")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *statsFormat != "json" && *statsFormat != "text" {
+		fmt.Fprintf(stderr, "unknown stats format <%s>, expected json or text\n", *statsFormat)
+		return exitError
+	}
+
+	paths, err := expandInputs(fs.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	inferrer := infer.New(infer.Options{MaxEnum: *maxEnum, MinEnumSamples: *minEnumSamples})
+	err = forEachListing(paths, stdin, func(l approach_3.Listing) error {
+		inferrer.Add(l)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	s, constraints := inferrer.Schema()
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(s); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	if *statsPath == "" {
+		return exitOK
+	}
+	out, closeOut, err := openOutput(*statsPath, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	defer closeOut()
+
+	if *statsFormat == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "\t")
+		err = enc.Encode(constraints)
+	} else {
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "PATH\tKEYWORD\tVALUE\tSUPPORT\tTOTAL\tCONFIDENCE\tNOTE")
+		for _, c := range constraints {
+			fmt.Fprintf(tw, "%s\t%s\t%v\t%d\t%d\t%.3f\t%s\n", c.Path, c.Keyword, c.Value, c.Support, c.Total, c.Confidence, c.Note)
+		}
+		err = tw.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	return exitOK
+}
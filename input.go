@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/overlay"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"cmenke/go-playground/lib/approach_3/store"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// errStop can be returned by a listing callback to stop reading inputs
+// without it being treated as a failure
+var errStop = errors.New("stop reading listings")
+
+func loadSchema(path string) (*schema.Schema, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file <%s>: %w", path, err)
+	}
+	defer file.Close()
+	s, err := schema.Load(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema file <%s>: %w", path, err)
+	}
+	return s, nil
+}
+
+// loadResolver loads the base schema and, when dir is set, the per-mls
+// overlays of dir, versioned by their content
+func loadResolver(schemaPath, dir string) (*overlay.Resolver, error) {
+	st, err := store.New(schemaPath, dir)
+	if err != nil {
+		return nil, err
+	}
+	return st.Current().Resolver, nil
+}
+
+// expandInputs resolves files and globs into a list of paths. "-" stands
+// for stdin and is also used when no input is given
+func expandInputs(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return []string{"-"}, nil
+	}
+
+	paths := []string{}
+	for _, arg := range args {
+		if arg == "-" || !strings.ContainsAny(arg, "*?[") {
+			paths = append(paths, arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob <%s>: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("glob <%s> did not match any file", arg)
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// forEachListing decodes every listing of every input and calls fn with
+// it. inputs hold a stream of json values, usually ndjson, each being
+// either a single listing or an array of listings
+func forEachListing(paths []string, stdin io.Reader, fn func(l approach_3.Listing) error) error {
+	for _, path := range paths {
+		if path == "-" {
+			if err := decodeListings("stdin", stdin, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open input <%s>: %w", path, err)
+		}
+		err = decodeListings(path, f, fn)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeListings(name string, r io.Reader, fn func(l approach_3.Listing) error) error {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode listing from <%s>: %w", name, err)
+		}
+
+		listings := []approach_3.Listing{}
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+			err = json.Unmarshal(raw, &listings)
+		} else {
+			var l approach_3.Listing
+			err = json.Unmarshal(raw, &l)
+			listings = append(listings, l)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal listing from <%s>: %w", name, err)
+		}
+
+		for _, l := range listings {
+			if err := fn(l); err != nil {
+				return err
+			}
+		}
+	}
+}
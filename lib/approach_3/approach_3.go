@@ -3,13 +3,36 @@ package approach_3
 import (
 	"cmenke/go-playground/lib/approach_3/report"
 	"cmenke/go-playground/lib/approach_3/schema"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
+	"time"
 )
 
+// ErrListingRejected is returned by Listing.Validate for a listing
+// failing as a whole, such as one with an unmapped key under the
+// schema.UnmappedReject policy. the listing is marked Rejected and its
+// key:values are reported with this error and cleared, so it is not
+// passed on but can be replayed from the reports
+var ErrListingRejected = errors.New("listing rejected")
+
 type Listing struct {
 	DocId string   `json:"docid"`
 	Mls   string   `json:"mls"`
 	Data  []KeyVal `json:"data"`
+	// Extras holds the key:values without a schema mapping quarantined
+	// by the schema.UnmappedQuarantine policy
+	Extras []KeyVal `json:"extras,omitempty"`
+	// SchemaVersion is the version of the schema the listing was last
+	// validated against, set by Validate
+	SchemaVersion string `json:"schema_version,omitempty"`
+	// Audit records the changes Validate made to values, such as unit
+	// conversions, with paths starting at the key
+	Audit []schema.AuditEntry `json:"audit,omitempty"`
+	// Rejected is set by Validate when the listing fails as a whole and
+	// must not be passed on, see ErrListingRejected
+	Rejected bool `json:"rejected,omitempty"`
 }
 
 type KeyVal struct {
@@ -28,33 +51,247 @@ func (rf ReporterFunc) Report(e report.BadKeyVal) error {
 	return rf(e)
 }
 
+// Observer can optionally be implemented by a Reporter to be told about
+// every validated key:value, not only the bad ones
+type Observer interface {
+	Observe(o report.Outcome)
+}
+
+// Validate validates every key:value of the listing against s, removing
+// the ones that fail from l.Data. failures are reported to r stamped with
+// the listing's docid, mls and the schema version, and returned joined
+// together. an unmapped key under the schema.UnmappedReject policy fails
+// the whole listing with ErrListingRejected
+func (l *Listing) Validate(s *schema.Schema, r Reporter, coerce bool) error {
+	if s == nil {
+		return errors.New("schema is nil, cannot validate listing")
+	}
+	newData := make([]KeyVal, 0, len(l.Data))
+	errs := []error{}
+	rejected := false
+	// accepted holds the valid key:values as sent, to be reported if the
+	// listing is rejected
+	accepted := []KeyVal{}
+	l.SchemaVersion = s.Version
+	// keys are siblings of one another by property name, so they are
+	// found when sent under an alias, the first value of a key is used
+	siblings := make(map[string]any, len(l.Data))
+	for _, e := range l.Data {
+		name, _, _ := s.Schemas(e.Key)
+		if _, ok := siblings[name]; !ok {
+			siblings[name] = e.Value
+		}
+	}
+	opts := schema.EvalOptions{
+		Coerce:   coerce,
+		Siblings: siblings,
+		Audit: func(e schema.AuditEntry) {
+			l.Audit = append(l.Audit, e)
+		},
+	}
+	for _, e := range l.Data {
+		validatedKeyVal, res, err := e.validate(s, r, opts, report.BadKeyVal{DocId: l.DocId, Mls: l.Mls, SchemaVersion: s.Version})
+		if res == report.ResultUnmapped && s.Unmapped == schema.UnmappedReject {
+			rejected = true
+		}
+		if err != nil {
+			errs = append(errs, err)
+			// a failure to report a valid key:value does not remove it
+			if res == report.ResultRejected {
+				continue
+			}
+		}
+		if res == report.ResultUnmapped {
+			switch s.Unmapped {
+			case schema.UnmappedDrop:
+				continue
+			case schema.UnmappedQuarantine:
+				l.Extras = append(l.Extras, validatedKeyVal)
+				continue
+			}
+		}
+		if res != report.ResultUnmapped {
+			accepted = append(accepted, e)
+		}
+		newData = append(newData, validatedKeyVal)
+	}
+	if rejected {
+		return l.reject(s, r, accepted, errs)
+	}
+	l.Data = newData
+	return errors.Join(errs...)
+}
+
+// reject marks l as rejected as a whole and clears its key:values. nothing
+// of it is kept, so the valid key:values in accepted are reported as sent
+// and not lost. errs are the failures that rejected it
+func (l *Listing) reject(s *schema.Schema, r Reporter, accepted []KeyVal, errs []error) error {
+	rejectErr := fmt.Errorf("%w <%s>, it has unmapped keys", ErrListingRejected, l.DocId)
+	for _, e := range accepted {
+		bad := report.BadKeyVal{DocId: l.DocId, Mls: l.Mls, SchemaVersion: s.Version, Value: e.Value, Error: rejectErr, Severity: report.SeverityError}
+		bad.Key, _, _ = s.Schemas(e.Key)
+		if bad.Key != e.Key {
+			bad.SourceKey = e.Key
+		}
+		if reportErr := r.Report(bad); reportErr != nil {
+			errs = append(errs, fmt.Errorf("failed to report key <%s> of rejected listing: %w", e.Key, reportErr))
+		}
+	}
+	l.Rejected = true
+	l.Data = nil
+	l.Extras = nil
+	return fmt.Errorf("%w <%s>: %w", ErrListingRejected, l.DocId, errors.Join(errs...))
+}
+
+// Validate validates the key:value against s. an unmapped key is
+// reported and only fails under the schema.UnmappedReject policy, the
+// policies dropping or quarantining it are applied by Listing.Validate.
+// a valid key:value r fails to take the report of is still returned,
+// along with the error of r
 func (kv *KeyVal) Validate(s *schema.Schema, r Reporter, coerce bool) (KeyVal, error) {
-	// check if key is specified in passed schema
-	s, ok := (*s.Properties)[kv.Key]
+	if s == nil {
+		return *kv, errors.New("schema is nil, cannot validate key:val")
+	}
+	validated, _, err := kv.validate(s, r, schema.EvalOptions{Coerce: coerce}, report.BadKeyVal{SchemaVersion: s.Version})
+	return validated, err
+}
+
+// reportUnmapped reports key, which has no schema mapping, to r with the
+// outcome of the x-unmapped policy of s. it returns the error failing the
+// listing under schema.UnmappedReject, joined with the error of r if any
+func reportUnmapped(s *schema.Schema, r Reporter, bad report.BadKeyVal, key string, value any) error {
+	bad.Key = key
+	bad.Value = value
+	bad.Event = report.EventUnmapped
+	bad.Severity = report.SeverityWarning
+	var err error
+	switch s.Unmapped {
+	case schema.UnmappedDrop:
+		bad.Error = fmt.Errorf("%w for key <%s>, dropped", schema.ErrUnmapped, key)
+	case schema.UnmappedQuarantine:
+		bad.Error = fmt.Errorf("%w for key <%s>, quarantined to extras", schema.ErrUnmapped, key)
+	case schema.UnmappedReject:
+		err = fmt.Errorf("%w for key <%s>, rejecting the listing", schema.ErrUnmapped, key)
+		bad.Error = err
+		bad.Severity = report.SeverityError
+	default:
+		bad.Error = fmt.Errorf("%w for key <%s>, accepted as is", schema.ErrUnmapped, key)
+	}
+	if reportErr := r.Report(bad); reportErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to report unmapped key <%s>: %w", key, reportErr))
+	}
+	return err
+}
+
+// validate does the work of Validate, using bad as the template for any
+// reported failure so listing level fields are carried along. it also
+// returns the result of the validation
+func (kv *KeyVal) validate(s *schema.Schema, r Reporter, opts schema.EvalOptions, bad report.BadKeyVal) (KeyVal, report.Result, error) {
+	start := time.Now()
+	// check if key is specified in passed schema, directly, through an
+	// alias or a pattern, and use the canonical key from here on
+	nameErr := s.EvalName(kv.Key)
+	key, schemas, ok := s.Schemas(kv.Key)
+	if key != kv.Key {
+		bad.SourceKey = kv.Key
+	}
+	bad.Event = report.EventInvalid
+	observe := func(res report.Result) {
+		if o, ok := r.(Observer); ok {
+			o.Observe(report.Outcome{
+				DocId:         bad.DocId,
+				Mls:           bad.Mls,
+				Key:           key,
+				Result:        res,
+				Duration:      time.Since(start),
+				SchemaVersion: bad.SchemaVersion,
+			})
+		}
+	}
+
+	if nameErr != nil {
+		// the key itself is not allowed, whatever its value
+		bad.Key = key
+		bad.Value = kv.Value
+		bad.Error = nameErr
+		bad.Severity = report.SeverityError
+		observe(report.ResultRejected)
+		if reportErr := r.Report(bad); reportErr != nil {
+			return *kv, report.ResultRejected, errors.Join(nameErr, fmt.Errorf("failed to report bad key <%s>: %w", key, reportErr))
+		}
+		return *kv, report.ResultRejected, nameErr
+	}
+
 	if !ok {
-		// if no schema specified, we accept the key:value as is
-		fmt.Printf("\nno schema mapping for key <%s>, continuing\n", kv.Key)
-		return *kv, nil
+		// if no schema specified, report the key and leave it to the
+		// policy of the schema
+		observe(report.ResultUnmapped)
+		return *kv, report.ResultUnmapped, reportUnmapped(s, r, bad, key, kv.Value)
+	}
+
+	// reportErr is a failure to report a valid key:value, which is kept
+	var reportErr error
+
+	// deprecated keys are still validated and kept, sending them is
+	// only reported
+	if depErr := s.EvalDeprecated(kv.Key); depErr != nil {
+		dep := bad
+		dep.Key = key
+		dep.Value = kv.Value
+		dep.Error = depErr
+		dep.Event = report.EventDeprecated
+		dep.Severity = report.SeverityWarning
+		if dErr := r.Report(dep); dErr != nil {
+			reportErr = fmt.Errorf("failed to report deprecated key <%s>: %w", key, dErr)
+		}
 	}
 
 	// if schema does exist for key:value, evaluate the value
-	// recursivly via the passed schema
-	val, err := s.Eval(kv.Value, coerce)
+	// recursivly via the passed schemas
+	opts.Path = "/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+	val, err := schema.EvalAll(schemas, kv.Value, opts)
+	if schema.IsWarning(err) {
+		// value is valid but suspicious, keep it and only report it
+		bad.Key = key
+		bad.Value = kv.Value
+		bad.Error = err
+		bad.Severity = report.SeverityWarning
+		if errors.Is(err, schema.ErrDeprecated) {
+			bad.Event = report.EventDeprecated
+		}
+		if wErr := r.Report(bad); wErr != nil {
+			reportErr = errors.Join(reportErr, fmt.Errorf("failed to report warning for key <%s>: %w", key, wErr))
+		}
+		err = nil
+	}
 	if err != nil {
 		// if schema for property fails to eval, report error to reporter
 		// and return the error
-		r.Report(report.BadKeyVal{
-			Key:   kv.Key,
-			Value: kv.Value,
-			Error: err,
-		})
-		return *kv, err
+		bad.Key = key
+		bad.Value = kv.Value
+		bad.Error = err
+		bad.Severity = report.SeverityError
+		observe(report.ResultRejected)
+		if bErr := r.Report(bad); bErr != nil {
+			reportErr = errors.Join(reportErr, fmt.Errorf("failed to report bad key <%s>: %w", key, bErr))
+		}
+		if reportErr != nil {
+			err = errors.Join(err, reportErr)
+		}
+		return *kv, report.ResultRejected, err
+	}
+
+	// a value that changed during eval was coerced into shape
+	res := report.ResultAccepted
+	if !reflect.DeepEqual(val, kv.Value) {
+		res = report.ResultCoerced
 	}
+	observe(res)
 
     // else if key:value is valid, return a new key:value as the Value
     // member could be coerced
 	return KeyVal{
-		Key:   kv.Key,
+		Key:   key,
 		Value: val,
-	}, nil
+	}, res, reportErr
 }
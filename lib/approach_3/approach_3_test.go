@@ -4,7 +4,9 @@ import (
 	"cmenke/go-playground/lib/approach_3/report"
 	"cmenke/go-playground/lib/approach_3/schema"
 	"encoding/json"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -629,6 +631,41 @@ func TestApproach3Coerce(t *testing.T) {
 		//////////////////////////////////////////////////////////
 		//                   coerce spec testing                //
 		//////////////////////////////////////////////////////////
+		{
+			description: "it should accept a value matching the first possible anyOf branch",
+			input: []KeyVal{
+				{
+					Key:   "LotSize",
+					Value: "1.5",
+				},
+			},
+			schema: []byte(`{
+ 			   "properties": {
+        			"LotSize": { "anyOf": [{ "type": "number" }, { "type": "string" }] }
+    			}
+			}`),
+			expectedOutput: []KeyVal{
+				{
+					Key:   "LotSize",
+					Value: 1.5,
+				},
+			},
+		},
+		{
+			description: "it should reject a value matching no anyOf branch",
+			input: []KeyVal{
+				{
+					Key:   "LotSize",
+					Value: true,
+				},
+			},
+			schema: []byte(`{
+ 			   "properties": {
+        			"LotSize": { "anyOf": [{ "type": "number" }, { "type": "string" }] }
+    			}
+			}`),
+			expectedOutput: []KeyVal{},
+		},
 		{
 			description: "it should accept a string value as a boolean type if parseable",
 			input: []KeyVal{
@@ -774,3 +811,495 @@ func TestApproach3Coerce(t *testing.T) {
 		}
 	}
 }
+
+func TestApproach3EnumRequired(t *testing.T) {
+	testCases := []struct {
+		description    string
+		input          []KeyVal
+		schema         []byte
+		expectedOutput []KeyVal
+		expectedKind   string
+	}{
+		{
+			description: "it should accept a value in the enum",
+			input: []KeyVal{
+				{
+					Key:   "Status",
+					Value: "Active",
+				},
+			},
+			schema: []byte(`{
+ 			   "properties": {
+        			"Status": { "type": "string", "enum": ["Active", "Pending"] }
+    			}
+			}`),
+			expectedOutput: []KeyVal{
+				{
+					Key:   "Status",
+					Value: "Active",
+				},
+			},
+		},
+		{
+			description: "it should reject a value not in the enum",
+			input: []KeyVal{
+				{
+					Key:   "Status",
+					Value: "Sold",
+				},
+			},
+			schema: []byte(`{
+ 			   "properties": {
+        			"Status": { "type": "string", "enum": ["Active", "Pending"] }
+    			}
+			}`),
+			expectedOutput: []KeyVal{},
+			expectedKind:   report.KindEnum,
+		},
+		{
+			description: "it should compare numbers to the enum by value",
+			input: []KeyVal{
+				{
+					Key:   "Stories",
+					Value: 2,
+				},
+			},
+			schema: []byte(`{
+ 			   "properties": {
+        			"Stories": { "type": "integer", "enum": [1, 2] }
+    			}
+			}`),
+			expectedOutput: []KeyVal{
+				{
+					Key:   "Stories",
+					Value: 2,
+				},
+			},
+		},
+		{
+			description: "it should accept an object with every required property",
+			input: []KeyVal{
+				{
+					Key:   "Office",
+					Value: map[string]any{"OfficeId": "A1", "Phone": "555"},
+				},
+			},
+			schema: []byte(`{
+ 			   "properties": {
+        			"Office": { "type": "object", "required": ["OfficeId"] }
+    			}
+			}`),
+			expectedOutput: []KeyVal{
+				{
+					Key:   "Office",
+					Value: map[string]any{"OfficeId": "A1", "Phone": "555"},
+				},
+			},
+		},
+		{
+			description: "it should reject an object missing a required property",
+			input: []KeyVal{
+				{
+					Key:   "Office",
+					Value: map[string]any{"Phone": "555"},
+				},
+			},
+			schema: []byte(`{
+ 			   "properties": {
+        			"Office": { "type": "object", "required": ["OfficeId"] }
+    			}
+			}`),
+			expectedOutput: []KeyVal{},
+			expectedKind:   report.KindRequired,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var s schema.Schema
+		err := json.Unmarshal(testCase.schema, &s)
+		if err != nil {
+			t.Fatalf("failed to unmarshal schema: %s", err)
+		}
+
+		testOutput := []KeyVal{}
+		for _, kv := range testCase.input {
+			validatedKV, err := kv.Validate(&s, ReporterFunc(func(e report.BadKeyVal) error { return nil }), true)
+			if err != nil {
+				if kind := report.Kind(err); kind != testCase.expectedKind {
+					t.Fatalf("%s: unexpected error <%s>", testCase.description, err)
+				}
+				continue
+			}
+			testOutput = append(testOutput, validatedKV)
+		}
+
+		if !reflect.DeepEqual(testOutput, testCase.expectedOutput) {
+			t.Fatalf("%s: testOutput <%v> does not match expected output <%v>", testCase.description, testOutput, testCase.expectedOutput)
+		}
+	}
+}
+
+func TestApproach3Severity(t *testing.T) {
+	testCases := []struct {
+		description      string
+		input            []KeyVal
+		schema           []byte
+		expectedOutput   []KeyVal
+		expectedSeverity []report.Severity
+	}{
+		{
+			description: "it should keep a value violating a warning node and report it as a warning",
+			input: []KeyVal{
+				{
+					Key:   "ListPrice",
+					Value: 60000000.0,
+				},
+			},
+			schema: []byte(`{
+ 			   "properties": {
+        			"ListPrice": { "type": "number", "maximum": 50000000, "x-severity": "warning" }
+    			}
+			}`),
+			expectedOutput: []KeyVal{
+				{
+					Key:   "ListPrice",
+					Value: 60000000.0,
+				},
+			},
+			expectedSeverity: []report.Severity{report.SeverityWarning},
+		},
+		{
+			description: "it should reject a value violating an error node",
+			input: []KeyVal{
+				{
+					Key:   "ListPrice",
+					Value: 60000000.0,
+				},
+			},
+			schema: []byte(`{
+ 			   "properties": {
+        			"ListPrice": { "type": "number", "maximum": 50000000 }
+    			}
+			}`),
+			expectedOutput:   []KeyVal{},
+			expectedSeverity: []report.Severity{report.SeverityError},
+		},
+		{
+			description: "it should still reject a value of the wrong type on a warning node",
+			input: []KeyVal{
+				{
+					Key:   "ListPrice",
+					Value: "lots",
+				},
+			},
+			schema: []byte(`{
+ 			   "properties": {
+        			"ListPrice": { "type": "number", "maximum": 50000000, "x-severity": "warning" }
+    			}
+			}`),
+			expectedOutput:   []KeyVal{},
+			expectedSeverity: []report.Severity{report.SeverityError},
+		},
+		{
+			description: "it should keep nested values violating a warning node",
+			input: []KeyVal{
+				{
+					Key:   "Rooms",
+					Value: []any{map[string]any{"Area": 1.0}, map[string]any{"Area": 9000.0}},
+				},
+			},
+			schema: []byte(`{
+ 			   "properties": {
+        			"Rooms": {
+						"type": "array",
+						"items": {
+							"type": "object",
+							"properties": {
+								"Area": { "type": "number", "minimum": 10, "x-severity": "warning" }
+							}
+						}
+					}
+    			}
+			}`),
+			expectedOutput: []KeyVal{
+				{
+					Key:   "Rooms",
+					Value: []any{map[string]any{"Area": 1.0}, map[string]any{"Area": 9000.0}},
+				},
+			},
+			expectedSeverity: []report.Severity{report.SeverityWarning},
+		},
+	}
+
+	for _, testCase := range testCases {
+		var s schema.Schema
+		err := json.Unmarshal(testCase.schema, &s)
+		if err != nil {
+			t.Fatalf("failed to unmarshal schema: %s", err)
+		}
+
+		testOutput := []KeyVal{}
+		severities := []report.Severity{}
+		reporter := ReporterFunc(func(e report.BadKeyVal) error {
+			severities = append(severities, e.Severity)
+			return nil
+		})
+		for _, kv := range testCase.input {
+			validatedKV, err := kv.Validate(&s, reporter, true)
+			if err != nil {
+				continue
+			}
+			testOutput = append(testOutput, validatedKV)
+		}
+
+		if !reflect.DeepEqual(testOutput, testCase.expectedOutput) {
+			t.Fatalf("%s: testOutput <%v> does not match expected output <%v>", testCase.description, testOutput, testCase.expectedOutput)
+		}
+		if !reflect.DeepEqual(severities, testCase.expectedSeverity) {
+			t.Fatalf("%s: reported severities <%v> do not match expected <%v>", testCase.description, severities, testCase.expectedSeverity)
+		}
+	}
+}
+
+func TestApproach3Aliases(t *testing.T) {
+	s, err := schema.Load(strings.NewReader(`{
+		"x-key-folding": ["case", "separators"],
+		"properties": {
+			"ListPrice": { "type": "number", "maximum": 50000000, "x-aliases": ["L_AskingPrice"] }
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+
+	l := Listing{Data: []KeyVal{
+		{Key: "ListPrice", Value: 1.0},
+		{Key: "list_price", Value: 2.0},
+		{Key: "LISTPRICE", Value: 3.0},
+		{Key: "L_AskingPrice", Value: 60000000.0},
+	}}
+	reported := []report.BadKeyVal{}
+	reporter := ReporterFunc(func(e report.BadKeyVal) error {
+		reported = append(reported, e)
+		return nil
+	})
+	_ = l.Validate(s, reporter, false)
+
+	expected := []KeyVal{
+		{Key: "ListPrice", Value: 1.0},
+		{Key: "ListPrice", Value: 2.0},
+		{Key: "ListPrice", Value: 3.0},
+	}
+	if !reflect.DeepEqual(l.Data, expected) {
+		t.Fatalf("data <%v> does not match expected <%v>", l.Data, expected)
+	}
+	if len(reported) != 1 || reported[0].Key != "ListPrice" || reported[0].SourceKey != "L_AskingPrice" {
+		t.Fatalf("expected the aliased key to be reported with its source key, got <%+v>", reported)
+	}
+}
+
+func TestApproach3AliasedUnitSibling(t *testing.T) {
+	s, err := schema.Load(strings.NewReader(`{
+		"x-key-folding": ["case", "separators"],
+		"properties": {
+			"LotSize": { "type": "number", "x-unit": "sqft", "x-unit-from": "LotSizeUnits" },
+			"LotSizeUnits": { "type": "string", "x-aliases": ["L_LotUnits"] }
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+
+	for _, key := range []string{"L_LotUnits", "lot_size_units"} {
+		l := Listing{Data: []KeyVal{{Key: "LotSize", Value: 2.0}, {Key: key, Value: "Acres"}}}
+		if err := l.Validate(s, nil, false); err != nil {
+			t.Fatalf("%s: unexpected error <%s>", key, err)
+		}
+		if l.Data[0].Value != 87120.0 {
+			t.Fatalf("%s: expected the lot size to be converted from the sibling unit, got <%v>", key, l.Data[0].Value)
+		}
+	}
+}
+
+func TestApproach3PatternProperties(t *testing.T) {
+	s, err := schema.Load(strings.NewReader(`{
+		"patternProperties": { "^Room\\d+Level$": { "enum": ["Main", "Upper", "Lower"] } },
+		"propertyNames": { "type": "string", "enum": ["Room1Level", "Room2Level", "Remarks"] }
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+
+	l := Listing{Data: []KeyVal{
+		{Key: "Room1Level", Value: "Main"},
+		{Key: "Room2Level", Value: "Attic"},
+		{Key: "Remarks", Value: "nice"},
+		{Key: "Room3Level", Value: "Main"},
+	}}
+	kinds := []string{}
+	reporter := ReporterFunc(func(e report.BadKeyVal) error {
+		kinds = append(kinds, e.Key+":"+report.Kind(e.Error))
+		return nil
+	})
+	_ = l.Validate(s, reporter, false)
+
+	expected := []KeyVal{
+		{Key: "Room1Level", Value: "Main"},
+		{Key: "Remarks", Value: "nice"},
+	}
+	if !reflect.DeepEqual(l.Data, expected) {
+		t.Fatalf("data <%v> does not match expected <%v>", l.Data, expected)
+	}
+	expectedKinds := []string{"Room2Level:" + report.KindEnum, "Remarks:" + report.KindUnmapped, "Room3Level:" + report.KindPropertyName}
+	if !reflect.DeepEqual(kinds, expectedKinds) {
+		t.Fatalf("reported <%v> do not match expected <%v>", kinds, expectedKinds)
+	}
+}
+
+func TestApproach3Unmapped(t *testing.T) {
+	testCases := []struct {
+		description    string
+		policy         string
+		expectedData   []KeyVal
+		expectedExtras []KeyVal
+		expectedErr    bool
+		// expectedRejected is set when the listing fails as a whole
+		expectedRejected bool
+		expectedEvents   []report.Event
+	}{
+		{
+			description:    "it should accept unmapped keys by default",
+			policy:         "",
+			expectedData:   []KeyVal{{Key: "ListPrice", Value: 1.0}, {Key: "DontMapMe", Value: "555-555-5555"}},
+			expectedEvents: []report.Event{report.EventUnmapped},
+		},
+		{
+			description:    "it should drop unmapped keys",
+			policy:         schema.UnmappedDrop,
+			expectedData:   []KeyVal{{Key: "ListPrice", Value: 1.0}},
+			expectedEvents: []report.Event{report.EventUnmapped},
+		},
+		{
+			description:    "it should quarantine unmapped keys to extras",
+			policy:         schema.UnmappedQuarantine,
+			expectedData:   []KeyVal{{Key: "ListPrice", Value: 1.0}},
+			expectedExtras: []KeyVal{{Key: "DontMapMe", Value: "555-555-5555"}},
+			expectedEvents: []report.Event{report.EventUnmapped},
+		},
+		{
+			description:      "it should reject the whole listing with unmapped keys",
+			policy:           schema.UnmappedReject,
+			expectedErr:      true,
+			expectedRejected: true,
+			// the valid ListPrice is reported along with the unmapped key
+			expectedEvents: []report.Event{report.EventUnmapped, ""},
+		},
+	}
+
+	for _, testCase := range testCases {
+		s := &schema.Schema{Properties: &map[string]*schema.Schema{"ListPrice": {Type: schema.Type{"number"}}}, Unmapped: testCase.policy}
+		l := Listing{Data: []KeyVal{
+			{Key: "ListPrice", Value: 1.0},
+			{Key: "DontMapMe", Value: "555-555-5555"},
+		}}
+		events := []report.Event{}
+		reporter := ReporterFunc(func(e report.BadKeyVal) error {
+			events = append(events, e.Event)
+			return nil
+		})
+		err := l.Validate(s, reporter, false)
+
+		if (err != nil) != testCase.expectedErr || (err != nil && !errors.Is(err, schema.ErrUnmapped)) {
+			t.Fatalf("%s: unexpected error <%v>", testCase.description, err)
+		}
+		if l.Rejected != testCase.expectedRejected || errors.Is(err, ErrListingRejected) != testCase.expectedRejected {
+			t.Fatalf("%s: expected rejected <%t>, got <%t> with error <%v>", testCase.description, testCase.expectedRejected, l.Rejected, err)
+		}
+		if !reflect.DeepEqual(l.Data, testCase.expectedData) {
+			t.Fatalf("%s: data <%v> does not match expected <%v>", testCase.description, l.Data, testCase.expectedData)
+		}
+		if !reflect.DeepEqual(l.Extras, testCase.expectedExtras) {
+			t.Fatalf("%s: extras <%v> do not match expected <%v>", testCase.description, l.Extras, testCase.expectedExtras)
+		}
+		if !reflect.DeepEqual(events, testCase.expectedEvents) {
+			t.Fatalf("%s: reported events <%v> do not match expected <%v>", testCase.description, events, testCase.expectedEvents)
+		}
+	}
+}
+
+func TestApproach3Deprecated(t *testing.T) {
+	s, err := schema.Load(strings.NewReader(`{
+		"properties": {
+			"ListPrice": { "type": "number" },
+			"LotSizeAcres": { "type": "number", "deprecated": true },
+			"Geo": { "type": "object", "properties": { "County": { "type": "string", "deprecated": true } } }
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	l := Listing{Data: []KeyVal{
+		{Key: "ListPrice", Value: 1.0},
+		{Key: "LotSizeAcres", Value: 2.0},
+		{Key: "Geo", Value: map[string]any{"County": "Travis"}},
+	}}
+	bad := []report.BadKeyVal{}
+	reporter := ReporterFunc(func(e report.BadKeyVal) error {
+		bad = append(bad, e)
+		return nil
+	})
+	if err := l.Validate(s, reporter, false); err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+
+	// deprecated keys are kept, at the top level and nested
+	if len(l.Data) != 3 {
+		t.Fatalf("expected every key to be kept, got <%v>", l.Data)
+	}
+	if len(bad) != 2 {
+		t.Fatalf("expected 2 reports, got <%+v>", bad)
+	}
+	for i, key := range []string{"LotSizeAcres", "Geo"} {
+		e := bad[i]
+		if e.Key != key || e.Event != report.EventDeprecated || e.Severity != report.SeverityWarning || report.Kind(e.Error) != report.KindDeprecated {
+			t.Fatalf("expected a deprecated warning for <%s>, got <%+v>", key, e)
+		}
+	}
+
+	// a reporter failing to take the warnings keeps the deprecated keys
+	reportErr := errors.New("alert channel is down")
+	l = Listing{Data: []KeyVal{
+		{Key: "LotSizeAcres", Value: 2.0},
+		{Key: "Geo", Value: map[string]any{"County": "Travis"}},
+	}}
+	failing := ReporterFunc(func(e report.BadKeyVal) error { return reportErr })
+	if err := l.Validate(s, failing, false); !errors.Is(err, reportErr) {
+		t.Fatalf("expected the reporter error, got <%v>", err)
+	}
+	if len(l.Data) != 2 {
+		t.Fatalf("expected every key to be kept, got <%v>", l.Data)
+	}
+}
+
+func TestApproach3SchemaShapes(t *testing.T) {
+	reporter := ReporterFunc(func(e report.BadKeyVal) error { return nil })
+	kv := KeyVal{Key: "AgentId", Value: "A1"}
+
+	// a schema without properties maps no key
+	s, err := schema.Load(strings.NewReader(`{ "type": "object" }`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	if _, err := kv.Validate(s, reporter, false); err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+
+	// a nil schema is an error, not a panic
+	if _, err := kv.Validate(nil, reporter, false); err == nil {
+		t.Fatalf("expected an error for a nil schema")
+	}
+	l := Listing{Data: []KeyVal{kv}}
+	if err := l.Validate(nil, reporter, false); err == nil {
+		t.Fatalf("expected an error for a nil schema")
+	}
+}
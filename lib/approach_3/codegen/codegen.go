@@ -0,0 +1,542 @@
+package codegen
+
+import (
+	"bytes"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"errors"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+)
+
+// Options configures the generated file
+type Options struct {
+	// Package is the package clause of the generated file
+	Package string
+	// Root names the struct generated for the root schema, Listing by default
+	Root string
+	// Source, if set, is mentioned in the generated file header
+	Source string
+}
+
+// Generate emits Go source with typed structs for the object schemas of s
+// and eval functions doing the same validation and coercion as s.Eval.
+// every struct gets an UnmarshalJSON going through its eval function and,
+// when the root schema has properties, a KeyVal type decodes listing
+// key:values by key. $ref, x-aliases, x-key-folding, x-transform, x-unit,
+// patternProperties, propertyNames and prefixItems are not supported, and coercers
+// registered with schema.RegisterCoercer are not used
+func Generate(s *schema.Schema, opts Options) ([]byte, error) {
+	if opts.Package == "" {
+		return nil, errors.New("a package name is required")
+	}
+	if opts.Root == "" {
+		opts.Root = "Listing"
+	}
+	if err := check(s, ""); err != nil {
+		return nil, err
+	}
+
+	g := &generator{names: map[string]bool{}, nodes: map[*schema.Schema]string{}}
+	g.assign(s, opts.Root)
+
+	source := "a schema.Schema"
+	if opts.Source != "" {
+		source = opts.Source
+	}
+	g.p("// Code generated by jsonschema gen from %s. DO NOT EDIT.\n\n", source)
+	g.p("package %s\n\n", opts.Package)
+	g.p("%s\n", preamble)
+
+	g.node(s, "")
+	if s.Properties != nil {
+		g.keyVal(s)
+	}
+
+	out, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid source: %w", err)
+	}
+	return out, nil
+}
+
+// check rejects the schemas Generate cannot mirror
+func check(s *schema.Schema, pointer string) error {
+	if s == nil {
+		return nil
+	}
+	if s.Ref != "" {
+		return fmt.Errorf("unsupported keyword <$ref> at <%s>", pointerOrRoot(pointer))
+	}
+	// generated code dispatches on exact keys
+	if s.Aliases != nil {
+		return fmt.Errorf("unsupported keyword <x-aliases> at <%s>", pointerOrRoot(pointer))
+	}
+	if s.KeyFolding != nil {
+		return fmt.Errorf("unsupported keyword <x-key-folding> at <%s>", pointerOrRoot(pointer))
+	}
+	if s.Unit != "" {
+		return fmt.Errorf("unsupported keyword <x-unit> at <%s>", pointerOrRoot(pointer))
+	}
+	if s.Transforms != nil {
+		return fmt.Errorf("unsupported keyword <x-transform> at <%s>", pointerOrRoot(pointer))
+	}
+	if s.PatternProperties != nil {
+		return fmt.Errorf("unsupported keyword <patternProperties> at <%s>", pointerOrRoot(pointer))
+	}
+	if s.PropertyNames != nil {
+		return fmt.Errorf("unsupported keyword <propertyNames> at <%s>", pointerOrRoot(pointer))
+	}
+	if s.PrefixItems != nil {
+		return fmt.Errorf("unsupported keyword <prefixItems> at <%s>", pointerOrRoot(pointer))
+	}
+	for _, t := range s.Type {
+		switch t {
+		case "null", "boolean", "integer", "number", "string", "array", "object":
+		default:
+			return fmt.Errorf("unknown type <%s> at <%s>", t, pointerOrRoot(pointer))
+		}
+	}
+	errs := []error{}
+	for _, k := range properties(s) {
+		errs = append(errs, check((*s.Properties)[k], pointer+"/properties/"+k))
+	}
+	errs = append(errs, check(s.Items, pointer+"/items"))
+	errs = append(errs, check(s.AdditionalProperties, pointer+"/additionalProperties"))
+	for i, b := range s.AnyOf {
+		errs = append(errs, check(b, fmt.Sprintf("%s/anyOf/%d", pointer, i)))
+	}
+	return errors.Join(errs...)
+}
+
+type generator struct {
+	buf bytes.Buffer
+	// names holds every identifier in use, nodes the one of each schema
+	names map[string]bool
+	nodes map[*schema.Schema]string
+}
+
+func (g *generator) p(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// assign names s and its children, children are prefixed by the name of
+// their parent, except for the root properties
+func (g *generator) assign(s *schema.Schema, name string) {
+	if s == nil {
+		return
+	}
+	if _, ok := g.nodes[s]; ok {
+		return
+	}
+	n := goName(name)
+	unique := n
+	for i := 2; g.names[unique] || reserved[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", n, i)
+	}
+	g.names[unique] = true
+	g.nodes[s] = unique
+
+	prefix := unique
+	if len(g.nodes) == 1 {
+		prefix = ""
+	}
+	for _, k := range properties(s) {
+		g.assign((*s.Properties)[k], prefix+goName(k))
+	}
+	g.assign(s.Items, unique+"Item")
+	g.assign(s.AdditionalProperties, unique+"Value")
+	for i, b := range s.AnyOf {
+		g.assign(b, fmt.Sprintf("%sBranch%d", unique, i))
+	}
+}
+
+// node emits the eval function of s, and its struct or converter when it
+// has a Go type of its own, followed by those of its children
+func (g *generator) node(s *schema.Schema, pointer string) {
+	if s == nil {
+		return
+	}
+	g.evalFunc(s, pointer)
+	if isStruct(s) {
+		g.structType(s, pointer)
+	} else if t := g.goType(s); (isArray(s) || isMap(s)) && t != "[]any" && t != "map[string]any" {
+		g.converter(s)
+	}
+
+	for _, k := range properties(s) {
+		g.node((*s.Properties)[k], pointer+"/properties/"+k)
+	}
+	g.node(s.Items, pointer+"/items")
+	g.node(s.AdditionalProperties, pointer+"/additionalProperties")
+	for i, b := range s.AnyOf {
+		g.node(b, fmt.Sprintf("%s/anyOf/%d", pointer, i))
+	}
+}
+
+func (g *generator) evalFunc(s *schema.Schema, pointer string) {
+	name := g.nodes[s]
+	g.p("// eval%s evaluates a value against <%s>\n", name, pointerOrRoot(pointer))
+	g.p("func eval%s(v any, coerce bool) (any, error) {\n", name)
+	g.p("warns := []error{}\n")
+
+	if len(s.Type) > 0 {
+		conds := []string{}
+		for _, t := range s.Type {
+			conds = append(conds, fmt.Sprintf("schema.MatchesType(v, %q)", t))
+		}
+		g.p("if !(%s) {\n", strings.Join(conds, " || "))
+		g.p("valType := schema.GetDataType(v)\n")
+		g.p("err := fmt.Errorf(\"%%w, the value <%%v> has the type <%%s> which does not match expected type(s) <%%v>\", schema.ErrTypeMismatch, v, valType, %s)\n", stringsLiteral(s.Type))
+		g.p("str, ok := v.(string)\n")
+		g.p("if !coerce || !ok {\nreturn v, err\n}\n")
+		g.p("var coerceErr error\n")
+		g.p("v, coerceErr = coerceString(str, %s)\n", quoted(s.Type))
+		g.p("if coerceErr != nil {\nreturn v, errors.Join(err, schema.ErrCoerce, coerceErr)\n}\n")
+		g.p("}\n")
+	}
+
+	bounded := s.Minimum != nil || s.Maximum != nil || s.ExclusiveMinimum != nil || s.ExclusiveMaximum != nil
+	if bounded || s.Enum != nil {
+		g.p("assertErrs := []error{}\n")
+		if bounded {
+			g.p("if n, ok := toFloat(v); ok {\n")
+			if s.Minimum != nil {
+				g.p("if n < %s {\nassertErrs = append(assertErrs, fmt.Errorf(\"%%w, the value <%%v> is less than the minimum <%%v>\", schema.ErrRange, v, %s))\n}\n", literal(*s.Minimum), literal(*s.Minimum))
+			}
+			if s.Maximum != nil {
+				g.p("if n > %s {\nassertErrs = append(assertErrs, fmt.Errorf(\"%%w, the value <%%v> is greater than the maximum <%%v>\", schema.ErrRange, v, %s))\n}\n", literal(*s.Maximum), literal(*s.Maximum))
+			}
+			if s.ExclusiveMinimum != nil {
+				g.p("if n <= %s {\nassertErrs = append(assertErrs, fmt.Errorf(\"%%w, the value <%%v> is not greater than the exclusive minimum <%%v>\", schema.ErrRange, v, %s))\n}\n", literal(*s.ExclusiveMinimum), literal(*s.ExclusiveMinimum))
+			}
+			if s.ExclusiveMaximum != nil {
+				g.p("if n >= %s {\nassertErrs = append(assertErrs, fmt.Errorf(\"%%w, the value <%%v> is not less than the exclusive maximum <%%v>\", schema.ErrRange, v, %s))\n}\n", literal(*s.ExclusiveMaximum), literal(*s.ExclusiveMaximum))
+			}
+			g.p("}\n")
+		}
+		if s.Enum != nil {
+			g.p("if enum := %s; !inEnum(enum, v) {\n", literal(s.Enum))
+			g.p("assertErrs = append(assertErrs, fmt.Errorf(\"%%w, the value <%%v> is not one of <%%v>\", schema.ErrEnum, v, enum))\n}\n")
+		}
+		g.p("if err := errors.Join(assertErrs...); err != nil {\n")
+		if s.Severity == schema.SeverityWarning {
+			g.p("warns = append(warns, err)\n")
+		} else {
+			g.p("return v, err\n")
+		}
+		g.p("}\n")
+	}
+
+	if len(s.AnyOf) > 0 {
+		funcs := []string{}
+		for _, b := range s.AnyOf {
+			if b != nil {
+				funcs = append(funcs, "eval"+g.nodes[b])
+			}
+		}
+		g.p("{\n")
+		g.p("errs := []error{}\nmatched := false\n")
+		g.p("for i, branch := range []func(any, bool) (any, error){%s} {\n", strings.Join(funcs, ", "))
+		g.p("bv, err := branch(v, coerce)\n")
+		g.p("if err == nil || schema.IsWarning(err) {\nv, matched = bv, true\nif err != nil {\nwarns = append(warns, err)\n}\nbreak\n}\n")
+		g.p("errs = append(errs, fmt.Errorf(\"branch %%d: %%w\", i, err))\n}\n")
+		g.p("if !matched {\nreturn v, errors.Join(append([]error{fmt.Errorf(\"%%w: %%v\", schema.ErrAnyOf, v)}, errs...)...)\n}\n")
+		g.p("}\n")
+	}
+
+	if s.Items != nil {
+		g.p("{\n")
+		g.p("items, ok := v.([]any)\n")
+		g.p("if !ok {\nreturn nil, fmt.Errorf(\"value <%%v> with type <%%T> cannot be evaluated as an array\", v, v)\n}\n")
+		g.p("valid := []any{}\nerrs := []error{}\nitemWarns := []error{}\n")
+		g.p("for i, item := range items {\n")
+		g.p("iv, err := eval%s(item, coerce)\n", g.nodes[s.Items])
+		g.p("if schema.IsWarning(err) {\nitemWarns = append(itemWarns, fmt.Errorf(\"item %%d: %%w\", i, err))\n} else if err != nil {\nerrs = append(errs, err)\ncontinue\n}\n")
+		g.p("valid = append(valid, iv)\n}\n")
+		g.p("if len(errs) > 0 {\nreturn nil, errors.Join(fmt.Errorf(\"%%w: %%v\", schema.ErrInvalidItems, items), fmt.Errorf(\"\\terror: could not validate all items in array: %%v\", errs))\n}\n")
+		g.p("if len(itemWarns) > 0 {\nwarns = append(warns, &schema.Warning{Err: errors.Join(itemWarns...)})\n}\n")
+		g.p("v = valid\n")
+		g.p("}\n")
+	}
+
+	if s.Properties != nil || s.Required != nil || s.AdditionalProperties != nil {
+		g.p("{\n")
+		g.p("obj, ok := v.(map[string]any)\n")
+		g.p("if !ok {\nreturn nil, fmt.Errorf(\"value <%%v> cannot be evaluated as an object\", v)\n}\n")
+		if len(s.Required) > 0 {
+			g.p("missing := []string{}\n")
+			g.p("for _, k := range %s {\nif _, ok := obj[k]; !ok {\nmissing = append(missing, k)\n}\n}\n", stringsLiteral(s.Required))
+			g.p("if len(missing) > 0 {\nreturn nil, errors.Join(fmt.Errorf(\"%%w: %%v\", schema.ErrInvalidObject, obj), fmt.Errorf(\"%%w(s) <%%v>\", schema.ErrRequired, missing))\n}\n")
+		}
+		if s.Properties != nil || s.AdditionalProperties != nil {
+			g.p("valid := map[string]any{}\nerrs := []error{}\npropWarns := []error{}\n")
+			// deprecated properties are kept but sending them is a warning
+			anyDeprecated := s.AdditionalProperties != nil && s.AdditionalProperties.Deprecated
+			for _, k := range properties(s) {
+				if c := (*s.Properties)[k]; c != nil && c.Deprecated {
+					anyDeprecated = true
+				}
+			}
+			g.p("for k, pv := range obj {\n")
+			g.p("var eval func(any, bool) (any, error)\n")
+			if anyDeprecated {
+				g.p("deprecated := false\n")
+			}
+			g.p("switch k {\n")
+			for _, k := range properties(s) {
+				if c := (*s.Properties)[k]; c != nil {
+					g.p("case %q:\neval = eval%s\n", k, g.nodes[c])
+					if c.Deprecated {
+						g.p("deprecated = true\n")
+					}
+				}
+			}
+			if s.AdditionalProperties != nil {
+				g.p("default:\neval = eval%s\n", g.nodes[s.AdditionalProperties])
+				if s.AdditionalProperties.Deprecated {
+					g.p("deprecated = true\n")
+				}
+			}
+			g.p("}\n")
+			g.p("if eval == nil {\nvalid[k] = pv\ncontinue\n}\n")
+			g.p("ev, err := eval(pv, coerce)\n")
+			if anyDeprecated {
+				g.p("if deprecated && (err == nil || schema.IsWarning(err)) {\nerr = &schema.Warning{Err: errors.Join(fmt.Errorf(\"%%w <%%s>, it is still being sent\", schema.ErrDeprecated, k), err)}\n}\n")
+			}
+			g.p("if schema.IsWarning(err) {\npropWarns = append(propWarns, fmt.Errorf(\"key <%%s>: %%w\", k, err))\n} else if err != nil {\nerrs = append(errs, fmt.Errorf(\"key <%%s>: %%w\", k, err))\ncontinue\n}\n")
+			g.p("valid[k] = ev\n}\n")
+			g.p("if len(errs) > 0 {\nreturn nil, errors.Join(fmt.Errorf(\"%%w: %%v\", schema.ErrInvalidObject, obj), fmt.Errorf(\"\\tcould not validate all key:vals in obj: %%v\", errs))\n}\n")
+			g.p("if len(propWarns) > 0 {\nwarns = append(warns, &schema.Warning{Err: errors.Join(propWarns...)})\n}\n")
+			g.p("v = valid\n")
+		}
+		g.p("}\n")
+	}
+
+	g.p("if len(warns) > 0 {\nreturn v, &schema.Warning{Err: errors.Join(warns...)}\n}\n")
+	g.p("return v, nil\n}\n\n")
+}
+
+// structType emits the struct of an object schema with its UnmarshalJSON
+// and converter
+func (g *generator) structType(s *schema.Schema, pointer string) {
+	name := g.nodes[s]
+	props := properties(s)
+	fields := fieldNames(props)
+
+	g.p("// %s is the Go type of <%s>\n", name, pointerOrRoot(pointer))
+	g.p("type %s struct {\n", name)
+	for _, k := range props {
+		c := (*s.Properties)[k]
+		g.fieldDoc(c)
+		g.p("%s %s `json:%q`\n", fields[k], g.fieldType(c), k+",omitempty")
+	}
+	g.p("}\n\n")
+
+	g.p("// UnmarshalJSON validates the value, coercing it when Coerce is set,\n// before decoding it\n")
+	g.p("func (x *%s) UnmarshalJSON(b []byte) error {\n", name)
+	g.p("var raw any\nif err := json.Unmarshal(b, &raw); err != nil {\nreturn err\n}\n")
+	g.p("v, err := eval%s(raw, Coerce)\n", name)
+	g.p("if err != nil && !schema.IsWarning(err) {\nreturn err\n}\n")
+	g.p("*x = conv%s(v)\nreturn nil\n}\n\n", name)
+
+	g.p("func conv%s(v any) %s {\n", name, name)
+	g.p("obj := v.(map[string]any)\nvar x %s\n", name)
+	for _, k := range props {
+		c := (*s.Properties)[k]
+		expr := g.convExpr(c, "fv")
+		if g.isPointer(c) {
+			expr = "ptr(" + expr + ")"
+		}
+		g.p("if fv, ok := obj[%q]; ok {\nx.%s = %s\n}\n", k, fields[k], expr)
+	}
+	g.p("return x\n}\n\n")
+}
+
+// converter emits the function converting an evaluated array or map into
+// its Go type
+func (g *generator) converter(s *schema.Schema) {
+	typ := g.goType(s)
+	g.p("func conv%s(v any) %s {\n", g.nodes[s], typ)
+	if isArray(s) {
+		g.p("items := v.([]any)\nout := make(%s, len(items))\n", typ)
+		g.p("for i, item := range items {\nout[i] = %s\n}\n", g.convExpr(s.Items, "item"))
+	} else {
+		g.p("obj := v.(map[string]any)\nout := make(%s, len(obj))\n", typ)
+		g.p("for k, item := range obj {\nout[k] = %s\n}\n", g.convExpr(s.AdditionalProperties, "item"))
+	}
+	g.p("return out\n}\n\n")
+}
+
+// keyVal emits Eval and the KeyVal type, dispatching on the root
+// properties the way Listing.Validate does
+func (g *generator) keyVal(s *schema.Schema) {
+	props := []string{}
+	for _, k := range properties(s) {
+		if (*s.Properties)[k] != nil {
+			props = append(props, k)
+		}
+	}
+
+	g.p("// Eval evaluates the value of key against its schema like schema.Eval.\n")
+	g.p("// known is false for keys without a schema, whose value is accepted as is\n")
+	g.p("func Eval(key string, v any, coerce bool) (val any, known bool, err error) {\n")
+	g.p("switch key {\n")
+	for _, k := range props {
+		g.p("case %q:\nval, err = eval%s(v, coerce)\nreturn val, true, err\n", k, g.nodes[(*s.Properties)[k]])
+	}
+	g.p("}\nreturn v, false, nil\n}\n\n")
+
+	g.p("// KeyVal is a key:value of listing data. UnmarshalJSON validates the\n")
+	g.p("// value of known keys and decodes it into its Go type\n")
+	g.p("type KeyVal struct {\nKey string `json:\"key\"`\nValue any `json:\"value\"`\n}\n\n")
+	g.p("func (kv *KeyVal) UnmarshalJSON(b []byte) error {\n")
+	g.p("var aux struct {\nKey string `json:\"key\"`\nValue any `json:\"value\"`\n}\n")
+	g.p("if err := json.Unmarshal(b, &aux); err != nil {\nreturn err\n}\n")
+	g.p("v, _, err := Eval(aux.Key, aux.Value, Coerce)\n")
+	g.p("if err != nil && !schema.IsWarning(err) {\nreturn fmt.Errorf(\"key <%%s>: %%w\", aux.Key, err)\n}\n")
+	g.p("kv.Key = aux.Key\n")
+	g.p("switch aux.Key {\n")
+	for _, k := range props {
+		g.p("case %q:\nkv.Value = %s\n", k, g.convExpr((*s.Properties)[k], "v"))
+	}
+	g.p("default:\nkv.Value = v\n}\n")
+	g.p("return nil\n}\n")
+}
+
+// goType returns the Go type values of s evaluate to, any when the schema
+// lets more than one type through
+func (g *generator) goType(s *schema.Schema) string {
+	if s == nil {
+		return "any"
+	}
+	switch {
+	case isStruct(s):
+		return g.nodes[s]
+	case isArray(s):
+		if s.Items == nil {
+			return "[]any"
+		}
+		return "[]" + g.goType(s.Items)
+	case isMap(s):
+		if s.AdditionalProperties == nil {
+			return "map[string]any"
+		}
+		return "map[string]" + g.goType(s.AdditionalProperties)
+	case onlyType(s, "string"):
+		return "string"
+	case onlyType(s, "boolean"):
+		return "bool"
+	case onlyType(s, "number"):
+		return "float64"
+	case onlyType(s, "integer"):
+		return "int"
+	}
+	return "any"
+}
+
+// fieldDoc emits the description of s as the doc comment of its field,
+// marking it deprecated when it is
+func (g *generator) fieldDoc(s *schema.Schema) {
+	if s == nil {
+		return
+	}
+	if s.Description != "" {
+		g.p("// %s\n", strings.ReplaceAll(strings.TrimSpace(s.Description), "\n", "\n// "))
+		if s.Deprecated {
+			g.p("//\n")
+		}
+	}
+	if s.Deprecated {
+		g.p("// Deprecated: the property is deprecated by the schema.\n")
+	}
+}
+
+// fieldType is the type of s as a struct field, a pointer for optional
+// scalars and structs
+func (g *generator) fieldType(s *schema.Schema) string {
+	if g.isPointer(s) {
+		return "*" + g.goType(s)
+	}
+	return g.goType(s)
+}
+
+func (g *generator) isPointer(s *schema.Schema) bool {
+	t := g.goType(s)
+	return t != "any" && !strings.HasPrefix(t, "[]") && !strings.HasPrefix(t, "map[")
+}
+
+// convExpr returns the expression converting expr, a value evaluated
+// against s, into the field type of s
+func (g *generator) convExpr(s *schema.Schema, expr string) string {
+	t := g.goType(s)
+	switch {
+	case t == "any":
+		return expr
+	case t == "[]any", t == "map[string]any", t == "string", t == "bool":
+		return expr + ".(" + t + ")"
+	case t == "float64":
+		return "toFloat64(" + expr + ")"
+	case t == "int":
+		return "toInt(" + expr + ")"
+	default:
+		return "conv" + g.nodes[s] + "(" + expr + ")"
+	}
+}
+
+// isStruct reports whether s always evaluates to an object with known
+// properties
+func isStruct(s *schema.Schema) bool {
+	return s.Properties != nil && (len(s.Type) == 0 && s.AnyOf == nil || onlyType(s, "object"))
+}
+
+// isArray reports whether s always evaluates to an array, either by type
+// or because items requires it
+func isArray(s *schema.Schema) bool {
+	return onlyType(s, "array") || s.Items != nil && len(s.Type) == 0 && s.AnyOf == nil
+}
+
+func isMap(s *schema.Schema) bool {
+	return s.Properties == nil && (onlyType(s, "object") || s.AdditionalProperties != nil && len(s.Type) == 0 && s.AnyOf == nil)
+}
+
+// onlyType reports whether the type of s is exactly t
+func onlyType(s *schema.Schema, t string) bool {
+	return s.AnyOf == nil && len(s.Type) == 1 && s.Type[0] == t
+}
+
+func properties(s *schema.Schema) []string {
+	if s == nil || s.Properties == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(*s.Properties))
+	for k := range *s.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fieldNames returns a unique exported field name for every property
+func fieldNames(props []string) map[string]string {
+	used := map[string]bool{}
+	names := map[string]string{}
+	for _, k := range props {
+		n := goName(k)
+		f := n
+		for i := 2; used[f]; i++ {
+			f = fmt.Sprintf("%s%d", n, i)
+		}
+		used[f] = true
+		names[k] = f
+	}
+	return names
+}
+
+func pointerOrRoot(pointer string) string {
+	if pointer == "" {
+		return "/"
+	}
+	return pointer
+}
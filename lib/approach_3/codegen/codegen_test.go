@@ -0,0 +1,133 @@
+package codegen
+
+import (
+	"bytes"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	testCases := []struct {
+		description string
+		schema      string
+		opts        Options
+		expected    []string
+		expectedErr string
+	}{
+		{
+			description: "it should emit typed structs, eval functions and KeyVal",
+			schema:      `{ "properties": { "list-price": { "type": "number" }, "Geo": { "type": "object", "properties": { "Lat": { "type": "number" } } } } }`,
+			opts:        Options{Package: "gen"},
+			expected: []string{
+				"package gen",
+				"type Listing struct",
+				"ListPrice *float64 `json:\"list-price,omitempty\"`",
+				"Geo       *Geo",
+				"type Geo struct",
+				"Lat *float64",
+				"func evalListPrice(v any, coerce bool) (any, error)",
+				"func Eval(key string, v any, coerce bool) (val any, known bool, err error)",
+				"type KeyVal struct",
+			},
+		},
+		{
+			description: "it should avoid name collisions",
+			schema:      `{ "properties": { "KeyVal": { "type": "string" }, "a": { "properties": { "b": {} } }, "A": {} } }`,
+			opts:        Options{Package: "gen", Root: "Doc"},
+			expected:    []string{"type Doc struct", "func evalKeyVal2(", "func evalA(", "type A2 struct", "func evalA2B("},
+		},
+		{
+			description: "it should check types like the schema package",
+			schema:      `{ "properties": { "Bedrooms": { "type": "integer" } } }`,
+			opts:        Options{Package: "gen"},
+			expected:    []string{`if !(schema.MatchesType(v, "integer")) {`},
+		},
+		{
+			description: "it should reject $ref",
+			schema:      `{ "$defs": { "price": { "type": "number" } }, "properties": { "ListPrice": { "$ref": "#/$defs/price" } } }`,
+			opts:        Options{Package: "gen"},
+			expectedErr: "unsupported keyword <$ref> at </properties/ListPrice>",
+		},
+		{
+			description: "it should reject x-aliases",
+			schema:      `{ "properties": { "ListPrice": { "type": "number", "x-aliases": ["L_AskingPrice"] } } }`,
+			opts:        Options{Package: "gen"},
+			expectedErr: "unsupported keyword <x-aliases> at </properties/ListPrice>",
+		},
+		{
+			description: "it should check exclusive bounds",
+			schema:      `{ "properties": { "Bedrooms": { "type": "integer", "exclusiveMinimum": 0 } } }`,
+			opts:        Options{Package: "gen"},
+			expected:    []string{"if n <= float64(0) {", "is not greater than the exclusive minimum"},
+		},
+		{
+			description: "it should document fields and warn about deprecated ones",
+			schema:      `{ "properties": { "Geo": { "type": "object", "properties": { "County": { "type": "string", "description": "county name", "deprecated": true } } } } }`,
+			opts:        Options{Package: "gen"},
+			expected: []string{
+				"// county name\n\t//\n\t// Deprecated: the property is deprecated by the schema.\n\tCounty *string",
+				"if deprecated && (err == nil || schema.IsWarning(err)) {",
+			},
+		},
+		{
+			description: "it should reject prefixItems",
+			schema:      `{ "properties": { "Geo": { "type": "array", "prefixItems": [ { "type": "number" } ] } } }`,
+			opts:        Options{Package: "gen"},
+			expectedErr: "unsupported keyword <prefixItems> at </properties/Geo>",
+		},
+		{
+			description: "it should require a package",
+			schema:      `{}`,
+			expectedErr: "a package name is required",
+		},
+	}
+
+	for _, testCase := range testCases {
+		s, err := schema.Load(strings.NewReader(testCase.schema))
+		if err != nil {
+			t.Fatalf("%s: failed to load schema: %s", testCase.description, err)
+		}
+		src, err := Generate(s, testCase.opts)
+		if testCase.expectedErr != "" {
+			if err == nil || !strings.Contains(err.Error(), testCase.expectedErr) {
+				t.Fatalf("%s: expected error containing <%s>, got <%v>", testCase.description, testCase.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error <%s>", testCase.description, err)
+		}
+		for _, e := range testCase.expected {
+			if !bytes.Contains(src, []byte(e)) {
+				t.Fatalf("%s: expected generated source to contain <%s>, got:\n%s", testCase.description, e, src)
+			}
+		}
+	}
+}
+
+// TestSampleIsUpToDate fails when the generator or testdata/sample.json
+// changed without running go generate ./lib/approach_3/codegen/...
+func TestSampleIsUpToDate(t *testing.T) {
+	f, err := os.Open("testdata/sample.json")
+	if err != nil {
+		t.Fatalf("failed to open schema: %s", err)
+	}
+	defer f.Close()
+	s, err := schema.Load(f)
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	expected, err := Generate(s, Options{Package: "sample", Root: "Sample", Source: "sample.json"})
+	if err != nil {
+		t.Fatalf("failed to generate: %s", err)
+	}
+	got, err := os.ReadFile("internal/sample/sample_gen.go")
+	if err != nil {
+		t.Fatalf("failed to read generated file: %s", err)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Fatalf("internal/sample/sample_gen.go is out of date, run go generate ./lib/approach_3/codegen/...")
+	}
+}
@@ -0,0 +1,216 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// reserved are the identifiers of the preamble and the generated api
+var reserved = map[string]bool{
+	"Coerce": true,
+	"Eval":   true,
+	"KeyVal": true,
+}
+
+// preamble holds the helpers every generated file relies on. coerceString
+// and inEnum mirror the unexported ones of the schema package
+const preamble = `import (
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Coerce tells UnmarshalJSON whether to coerce stringified values into
+// their schema type
+var Coerce = true
+
+// coerceString tries to parse v as each of types in order, returning the
+// first that works
+func coerceString(v string, types ...string) (any, error) {
+	var err error
+	for _, t := range types {
+		switch t {
+		case "null":
+			return nil, nil
+		case "boolean":
+			if v == "true" {
+				return true, nil
+			} else if v == "false" {
+				return false, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to boolean", v), err)
+		case "integer":
+			intVal, intErr := strconv.Atoi(v)
+			if intErr == nil {
+				return intVal, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to integer: %s", v, intErr), err)
+		case "number":
+			intVal, intErr := strconv.Atoi(v)
+			if intErr == nil {
+				return intVal, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to integer: %s", v, intErr), err)
+			floatVal, floatErr := strconv.ParseFloat(v, 64)
+			if floatErr == nil {
+				return floatVal, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to float: %s", v, floatErr), err)
+		case "array":
+			var arr []any
+			arrErr := json.Unmarshal([]byte(v), &arr)
+			if arrErr == nil {
+				return arr, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to array: %s", v, arrErr), err)
+		case "object":
+			var obj map[string]any
+			objErr := json.Unmarshal([]byte(v), &obj)
+			if objErr == nil {
+				return obj, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to obj: %s", v, objErr), err)
+		}
+	}
+	return nil, err
+}
+
+// toFloat returns v as a float64 if it is a number
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func toFloat64(v any) float64 {
+	n, _ := toFloat(v)
+	return n
+}
+
+func toInt(v any) int {
+	if n, ok := v.(int); ok {
+		return n
+	}
+	return int(toFloat64(v))
+}
+
+// inEnum reports whether v is one of enum, comparing numbers by value
+func inEnum(enum []any, v any) bool {
+	n, isNum := toFloat(v)
+	for _, e := range enum {
+		if en, ok := toFloat(e); ok && isNum {
+			if en == n {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+`
+
+// goName turns a property key into an exported Go identifier
+func goName(k string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range k {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// literal returns the Go expression of a value decoded from json
+func literal(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		return "float64(" + strconv.FormatFloat(val, 'g', -1, 64) + ")"
+	case string:
+		return strconv.Quote(val)
+	case []any:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = literal(item)
+		}
+		return "[]any{" + strings.Join(items, ", ") + "}"
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, k := range keys {
+			items[i] = strconv.Quote(k) + ": " + literal(val[k])
+		}
+		return "map[string]any{" + strings.Join(items, ", ") + "}"
+	default:
+		return fmt.Sprintf("%#v", v)
+	}
+}
+
+func stringsLiteral(ss []string) string {
+	return "[]string{" + quoted(ss) + "}"
+}
+
+// quoted returns ss as a comma separated list of Go strings
+func quoted(ss []string) string {
+	q := make([]string, len(ss))
+	for i, s := range ss {
+		q[i] = strconv.Quote(s)
+	}
+	return strings.Join(q, ", ")
+}
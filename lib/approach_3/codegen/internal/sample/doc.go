@@ -0,0 +1,6 @@
+// Package sample is generated from codegen/testdata/sample.json, covering
+// the keywords schema.json does not use, to check the generated code
+// against the interpreter.
+package sample
+
+//go:generate go run ../../../../.. gen --schema ../../testdata/sample.json --package sample --root Sample --out sample_gen.go
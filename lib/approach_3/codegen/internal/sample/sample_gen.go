@@ -0,0 +1,760 @@
+// Code generated by jsonschema gen from sample.json. DO NOT EDIT.
+
+package sample
+
+import (
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Coerce tells UnmarshalJSON whether to coerce stringified values into
+// their schema type
+var Coerce = true
+
+// coerceString tries to parse v as each of types in order, returning the
+// first that works
+func coerceString(v string, types ...string) (any, error) {
+	var err error
+	for _, t := range types {
+		switch t {
+		case "null":
+			return nil, nil
+		case "boolean":
+			if v == "true" {
+				return true, nil
+			} else if v == "false" {
+				return false, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to boolean", v), err)
+		case "integer":
+			intVal, intErr := strconv.Atoi(v)
+			if intErr == nil {
+				return intVal, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to integer: %s", v, intErr), err)
+		case "number":
+			intVal, intErr := strconv.Atoi(v)
+			if intErr == nil {
+				return intVal, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to integer: %s", v, intErr), err)
+			floatVal, floatErr := strconv.ParseFloat(v, 64)
+			if floatErr == nil {
+				return floatVal, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to float: %s", v, floatErr), err)
+		case "array":
+			var arr []any
+			arrErr := json.Unmarshal([]byte(v), &arr)
+			if arrErr == nil {
+				return arr, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to array: %s", v, arrErr), err)
+		case "object":
+			var obj map[string]any
+			objErr := json.Unmarshal([]byte(v), &obj)
+			if objErr == nil {
+				return obj, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to obj: %s", v, objErr), err)
+		}
+	}
+	return nil, err
+}
+
+// toFloat returns v as a float64 if it is a number
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func toFloat64(v any) float64 {
+	n, _ := toFloat(v)
+	return n
+}
+
+func toInt(v any) int {
+	if n, ok := v.(int); ok {
+		return n
+	}
+	return int(toFloat64(v))
+}
+
+// inEnum reports whether v is one of enum, comparing numbers by value
+func inEnum(enum []any, v any) bool {
+	n, isNum := toFloat(v)
+	for _, e := range enum {
+		if en, ok := toFloat(e); ok && isNum {
+			if en == n {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// evalSample evaluates a value against </>
+func evalSample(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "object")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"object"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "object")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	{
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("value <%v> cannot be evaluated as an object", v)
+		}
+		missing := []string{}
+		for _, k := range []string{"Status"} {
+			if _, ok := obj[k]; !ok {
+				missing = append(missing, k)
+			}
+		}
+		if len(missing) > 0 {
+			return nil, errors.Join(fmt.Errorf("%w: %v", schema.ErrInvalidObject, obj), fmt.Errorf("%w(s) <%v>", schema.ErrRequired, missing))
+		}
+		valid := map[string]any{}
+		errs := []error{}
+		propWarns := []error{}
+		for k, pv := range obj {
+			var eval func(any, bool) (any, error)
+			deprecated := false
+			switch k {
+			case "Beds":
+				eval = evalBeds
+			case "County":
+				eval = evalCounty
+				deprecated = true
+			case "Extra":
+				eval = evalExtra
+			case "Pool":
+				eval = evalPool
+			case "Price":
+				eval = evalPrice
+			case "Rooms":
+				eval = evalRooms
+			case "Status":
+				eval = evalStatus
+			case "Tags":
+				eval = evalTags
+			}
+			if eval == nil {
+				valid[k] = pv
+				continue
+			}
+			ev, err := eval(pv, coerce)
+			if deprecated && (err == nil || schema.IsWarning(err)) {
+				err = &schema.Warning{Err: errors.Join(fmt.Errorf("%w <%s>, it is still being sent", schema.ErrDeprecated, k), err)}
+			}
+			if schema.IsWarning(err) {
+				propWarns = append(propWarns, fmt.Errorf("key <%s>: %w", k, err))
+			} else if err != nil {
+				errs = append(errs, fmt.Errorf("key <%s>: %w", k, err))
+				continue
+			}
+			valid[k] = ev
+		}
+		if len(errs) > 0 {
+			return nil, errors.Join(fmt.Errorf("%w: %v", schema.ErrInvalidObject, obj), fmt.Errorf("\tcould not validate all key:vals in obj: %v", errs))
+		}
+		if len(propWarns) > 0 {
+			warns = append(warns, &schema.Warning{Err: errors.Join(propWarns...)})
+		}
+		v = valid
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// Sample is the Go type of </>
+type Sample struct {
+	Beds *int `json:"Beds,omitempty"`
+	// county name, sent in Geo by newer feeds
+	//
+	// Deprecated: the property is deprecated by the schema.
+	County *string               `json:"County,omitempty"`
+	Extra  any                   `json:"Extra,omitempty"`
+	Pool   any                   `json:"Pool,omitempty"`
+	Price  any                   `json:"Price,omitempty"`
+	Rooms  map[string]RoomsValue `json:"Rooms,omitempty"`
+	Status *string               `json:"Status,omitempty"`
+	Tags   []any                 `json:"Tags,omitempty"`
+}
+
+// UnmarshalJSON validates the value, coercing it when Coerce is set,
+// before decoding it
+func (x *Sample) UnmarshalJSON(b []byte) error {
+	var raw any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	v, err := evalSample(raw, Coerce)
+	if err != nil && !schema.IsWarning(err) {
+		return err
+	}
+	*x = convSample(v)
+	return nil
+}
+
+func convSample(v any) Sample {
+	obj := v.(map[string]any)
+	var x Sample
+	if fv, ok := obj["Beds"]; ok {
+		x.Beds = ptr(toInt(fv))
+	}
+	if fv, ok := obj["County"]; ok {
+		x.County = ptr(fv.(string))
+	}
+	if fv, ok := obj["Extra"]; ok {
+		x.Extra = fv
+	}
+	if fv, ok := obj["Pool"]; ok {
+		x.Pool = fv
+	}
+	if fv, ok := obj["Price"]; ok {
+		x.Price = fv
+	}
+	if fv, ok := obj["Rooms"]; ok {
+		x.Rooms = convRooms(fv)
+	}
+	if fv, ok := obj["Status"]; ok {
+		x.Status = ptr(fv.(string))
+	}
+	if fv, ok := obj["Tags"]; ok {
+		x.Tags = fv.([]any)
+	}
+	return x
+}
+
+// evalBeds evaluates a value against </properties/Beds>
+func evalBeds(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "integer")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"integer"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "integer")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	assertErrs := []error{}
+	if n, ok := toFloat(v); ok {
+		if n < float64(0) {
+			assertErrs = append(assertErrs, fmt.Errorf("%w, the value <%v> is less than the minimum <%v>", schema.ErrRange, v, float64(0)))
+		}
+		if n > float64(50) {
+			assertErrs = append(assertErrs, fmt.Errorf("%w, the value <%v> is greater than the maximum <%v>", schema.ErrRange, v, float64(50)))
+		}
+	}
+	if err := errors.Join(assertErrs...); err != nil {
+		warns = append(warns, err)
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalCounty evaluates a value against </properties/County>
+func evalCounty(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "string")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"string"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "string")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalExtra evaluates a value against </properties/Extra>
+func evalExtra(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalPool evaluates a value against </properties/Pool>
+func evalPool(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "boolean") || schema.MatchesType(v, "null")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"boolean", "null"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "boolean", "null")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalPrice evaluates a value against </properties/Price>
+func evalPrice(v any, coerce bool) (any, error) {
+	warns := []error{}
+	{
+		errs := []error{}
+		matched := false
+		for i, branch := range []func(any, bool) (any, error){evalPriceBranch0, evalPriceBranch1} {
+			bv, err := branch(v, coerce)
+			if err == nil || schema.IsWarning(err) {
+				v, matched = bv, true
+				if err != nil {
+					warns = append(warns, err)
+				}
+				break
+			}
+			errs = append(errs, fmt.Errorf("branch %d: %w", i, err))
+		}
+		if !matched {
+			return v, errors.Join(append([]error{fmt.Errorf("%w: %v", schema.ErrAnyOf, v)}, errs...)...)
+		}
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalPriceBranch0 evaluates a value against </properties/Price/anyOf/0>
+func evalPriceBranch0(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "number")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"number"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "number")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	assertErrs := []error{}
+	if n, ok := toFloat(v); ok {
+		if n < float64(0) {
+			assertErrs = append(assertErrs, fmt.Errorf("%w, the value <%v> is less than the minimum <%v>", schema.ErrRange, v, float64(0)))
+		}
+	}
+	if err := errors.Join(assertErrs...); err != nil {
+		return v, err
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalPriceBranch1 evaluates a value against </properties/Price/anyOf/1>
+func evalPriceBranch1(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "string")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"string"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "string")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	assertErrs := []error{}
+	if enum := []any{"TBD"}; !inEnum(enum, v) {
+		assertErrs = append(assertErrs, fmt.Errorf("%w, the value <%v> is not one of <%v>", schema.ErrEnum, v, enum))
+	}
+	if err := errors.Join(assertErrs...); err != nil {
+		return v, err
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalRooms evaluates a value against </properties/Rooms>
+func evalRooms(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "object")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"object"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "object")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	{
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("value <%v> cannot be evaluated as an object", v)
+		}
+		valid := map[string]any{}
+		errs := []error{}
+		propWarns := []error{}
+		for k, pv := range obj {
+			var eval func(any, bool) (any, error)
+			switch k {
+			default:
+				eval = evalRoomsValue
+			}
+			if eval == nil {
+				valid[k] = pv
+				continue
+			}
+			ev, err := eval(pv, coerce)
+			if schema.IsWarning(err) {
+				propWarns = append(propWarns, fmt.Errorf("key <%s>: %w", k, err))
+			} else if err != nil {
+				errs = append(errs, fmt.Errorf("key <%s>: %w", k, err))
+				continue
+			}
+			valid[k] = ev
+		}
+		if len(errs) > 0 {
+			return nil, errors.Join(fmt.Errorf("%w: %v", schema.ErrInvalidObject, obj), fmt.Errorf("\tcould not validate all key:vals in obj: %v", errs))
+		}
+		if len(propWarns) > 0 {
+			warns = append(warns, &schema.Warning{Err: errors.Join(propWarns...)})
+		}
+		v = valid
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+func convRooms(v any) map[string]RoomsValue {
+	obj := v.(map[string]any)
+	out := make(map[string]RoomsValue, len(obj))
+	for k, item := range obj {
+		out[k] = convRoomsValue(item)
+	}
+	return out
+}
+
+// evalRoomsValue evaluates a value against </properties/Rooms/additionalProperties>
+func evalRoomsValue(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "object")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"object"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "object")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	{
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("value <%v> cannot be evaluated as an object", v)
+		}
+		missing := []string{}
+		for _, k := range []string{"Area"} {
+			if _, ok := obj[k]; !ok {
+				missing = append(missing, k)
+			}
+		}
+		if len(missing) > 0 {
+			return nil, errors.Join(fmt.Errorf("%w: %v", schema.ErrInvalidObject, obj), fmt.Errorf("%w(s) <%v>", schema.ErrRequired, missing))
+		}
+		valid := map[string]any{}
+		errs := []error{}
+		propWarns := []error{}
+		for k, pv := range obj {
+			var eval func(any, bool) (any, error)
+			switch k {
+			case "Area":
+				eval = evalRoomsValueArea
+			}
+			if eval == nil {
+				valid[k] = pv
+				continue
+			}
+			ev, err := eval(pv, coerce)
+			if schema.IsWarning(err) {
+				propWarns = append(propWarns, fmt.Errorf("key <%s>: %w", k, err))
+			} else if err != nil {
+				errs = append(errs, fmt.Errorf("key <%s>: %w", k, err))
+				continue
+			}
+			valid[k] = ev
+		}
+		if len(errs) > 0 {
+			return nil, errors.Join(fmt.Errorf("%w: %v", schema.ErrInvalidObject, obj), fmt.Errorf("\tcould not validate all key:vals in obj: %v", errs))
+		}
+		if len(propWarns) > 0 {
+			warns = append(warns, &schema.Warning{Err: errors.Join(propWarns...)})
+		}
+		v = valid
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// RoomsValue is the Go type of </properties/Rooms/additionalProperties>
+type RoomsValue struct {
+	Area *float64 `json:"Area,omitempty"`
+}
+
+// UnmarshalJSON validates the value, coercing it when Coerce is set,
+// before decoding it
+func (x *RoomsValue) UnmarshalJSON(b []byte) error {
+	var raw any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	v, err := evalRoomsValue(raw, Coerce)
+	if err != nil && !schema.IsWarning(err) {
+		return err
+	}
+	*x = convRoomsValue(v)
+	return nil
+}
+
+func convRoomsValue(v any) RoomsValue {
+	obj := v.(map[string]any)
+	var x RoomsValue
+	if fv, ok := obj["Area"]; ok {
+		x.Area = ptr(toFloat64(fv))
+	}
+	return x
+}
+
+// evalRoomsValueArea evaluates a value against </properties/Rooms/additionalProperties/properties/Area>
+func evalRoomsValueArea(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "number")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"number"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "number")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalStatus evaluates a value against </properties/Status>
+func evalStatus(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "string")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"string"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "string")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	assertErrs := []error{}
+	if enum := []any{"Active", "Pending", "Closed"}; !inEnum(enum, v) {
+		assertErrs = append(assertErrs, fmt.Errorf("%w, the value <%v> is not one of <%v>", schema.ErrEnum, v, enum))
+	}
+	if err := errors.Join(assertErrs...); err != nil {
+		return v, err
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalTags evaluates a value against </properties/Tags>
+func evalTags(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "array")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"array"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "array")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// Eval evaluates the value of key against its schema like schema.Eval.
+// known is false for keys without a schema, whose value is accepted as is
+func Eval(key string, v any, coerce bool) (val any, known bool, err error) {
+	switch key {
+	case "Beds":
+		val, err = evalBeds(v, coerce)
+		return val, true, err
+	case "County":
+		val, err = evalCounty(v, coerce)
+		return val, true, err
+	case "Extra":
+		val, err = evalExtra(v, coerce)
+		return val, true, err
+	case "Pool":
+		val, err = evalPool(v, coerce)
+		return val, true, err
+	case "Price":
+		val, err = evalPrice(v, coerce)
+		return val, true, err
+	case "Rooms":
+		val, err = evalRooms(v, coerce)
+		return val, true, err
+	case "Status":
+		val, err = evalStatus(v, coerce)
+		return val, true, err
+	case "Tags":
+		val, err = evalTags(v, coerce)
+		return val, true, err
+	}
+	return v, false, nil
+}
+
+// KeyVal is a key:value of listing data. UnmarshalJSON validates the
+// value of known keys and decodes it into its Go type
+type KeyVal struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+func (kv *KeyVal) UnmarshalJSON(b []byte) error {
+	var aux struct {
+		Key   string `json:"key"`
+		Value any    `json:"value"`
+	}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	v, _, err := Eval(aux.Key, aux.Value, Coerce)
+	if err != nil && !schema.IsWarning(err) {
+		return fmt.Errorf("key <%s>: %w", aux.Key, err)
+	}
+	kv.Key = aux.Key
+	switch aux.Key {
+	case "Beds":
+		kv.Value = toInt(v)
+	case "County":
+		kv.Value = v.(string)
+	case "Extra":
+		kv.Value = v
+	case "Pool":
+		kv.Value = v
+	case "Price":
+		kv.Value = v
+	case "Rooms":
+		kv.Value = convRooms(v)
+	case "Status":
+		kv.Value = v.(string)
+	case "Tags":
+		kv.Value = v.([]any)
+	default:
+		kv.Value = v
+	}
+	return nil
+}
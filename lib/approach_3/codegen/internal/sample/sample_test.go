@@ -0,0 +1,119 @@
+package sample
+
+import (
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+var sentinels = []error{
+	schema.ErrTypeMismatch,
+	schema.ErrCoerce,
+	schema.ErrInvalidItems,
+	schema.ErrInvalidObject,
+	schema.ErrRange,
+	schema.ErrEnum,
+	schema.ErrRequired,
+	schema.ErrAnyOf,
+	schema.ErrDeprecated,
+}
+
+func loadSchema(t testing.TB) *schema.Schema {
+	t.Helper()
+	f, err := os.Open("../../testdata/sample.json")
+	if err != nil {
+		t.Fatalf("failed to open schema: %s", err)
+	}
+	defer f.Close()
+	s, err := schema.Load(f)
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	return s
+}
+
+// agree evaluates the json value against the whole sample schema both ways
+// and fails unless the results match
+func agree(t testing.TB, s *schema.Schema, value string, coerce bool) {
+	t.Helper()
+	var genIn, intIn any
+	if json.Unmarshal([]byte(value), &genIn) != nil || json.Unmarshal([]byte(value), &intIn) != nil {
+		t.Skip()
+	}
+	genVal, genErr := evalSample(genIn, coerce)
+	intVal, intErr := s.Eval(intIn, coerce)
+
+	if !reflect.DeepEqual(genVal, intVal) {
+		t.Fatalf("value <%s> coerce <%v>: generated value <%#v> does not match interpreted <%#v>", value, coerce, genVal, intVal)
+	}
+	if (genErr == nil) != (intErr == nil) || schema.IsWarning(genErr) != schema.IsWarning(intErr) {
+		t.Fatalf("value <%s> coerce <%v>: generated error <%v> does not match interpreted <%v>", value, coerce, genErr, intErr)
+	}
+	for _, sentinel := range sentinels {
+		if errors.Is(genErr, sentinel) != errors.Is(intErr, sentinel) {
+			t.Fatalf("value <%s> coerce <%v>: generated error <%v> and interpreted <%v> disagree on <%s>", value, coerce, genErr, intErr, sentinel)
+		}
+	}
+}
+
+var seeds = []string{
+	`{"Status": "Active"}`,
+	`{"Status": "Sold"}`,
+	`{"Beds": 3}`,
+	`{"Status": "Active", "Beds": "3"}`,
+	`{"Status": "Active", "Beds": "300"}`,
+	`{"Status": "Active", "Pool": "true", "Price": "TBD"}`,
+	`{"Status": "Active", "Pool": null, "Price": -1}`,
+	`{"Status": "Active", "Price": "100"}`,
+	`{"Status": "Active", "Rooms": {"Kitchen": {"Area": "12.5"}, "Den": {}}}`,
+	`{"Status": "Active", "Rooms": "{\"Kitchen\": {\"Area\": 12}}"}`,
+	`{"Status": "Active", "Tags": "[1, \"a\"]", "Extra": [1]}`,
+	`{"Status": "Active", "County": "Travis", "Beds": 300}`,
+	`{"Status": "Active", "County": 1}`,
+	`"{\"Status\": \"Closed\"}"`,
+	`[]`,
+}
+
+func TestGeneratedMatchesInterpreter(t *testing.T) {
+	s := loadSchema(t)
+	for _, seed := range seeds {
+		for _, coerce := range []bool{true, false} {
+			agree(t, s, seed, coerce)
+		}
+	}
+}
+
+func FuzzGeneratedMatchesInterpreter(f *testing.F) {
+	s := loadSchema(f)
+	for _, seed := range seeds {
+		f.Add(seed, true)
+	}
+	f.Fuzz(func(t *testing.T, value string, coerce bool) {
+		agree(t, s, value, coerce)
+	})
+}
+
+func TestTypedDecoding(t *testing.T) {
+	var got Sample
+	err := json.Unmarshal([]byte(`{"Status": "Active", "Beds": "3", "Price": "TBD", "Rooms": {"Kitchen": {"Area": "12.5"}}}`), &got)
+	if err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	status, beds := "Active", 3
+	expected := Sample{
+		Status: &status,
+		Beds:   &beds,
+		Price:  "TBD",
+		Rooms:  map[string]RoomsValue{"Kitchen": {Area: ptr(12.5)}},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected <%+v>, got <%+v>", expected, got)
+	}
+
+	if err := json.Unmarshal([]byte(`{"Beds": 3}`), &got); !errors.Is(err, schema.ErrRequired) {
+		t.Fatalf("expected a missing Status to fail decoding, got <%v>", err)
+	}
+}
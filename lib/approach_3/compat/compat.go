@@ -0,0 +1,433 @@
+package compat
+
+import (
+	"cmenke/go-playground/lib/approach_3/schema"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Kind classifies a schema change by the values it lets through
+type Kind string
+
+const (
+	// Widening changes accept every value accepted before, and more
+	Widening Kind = "widening"
+	// Narrowing changes only accept a subset of the values accepted before
+	Narrowing Kind = "narrowing"
+	// Incompatible changes accept values rejected before while rejecting
+	// values accepted before
+	Incompatible Kind = "incompatible"
+)
+
+// Change is a single difference between two schemas
+type Change struct {
+	Path        string `json:"path"` // json pointer into the schemas
+	Keyword     string `json:"keyword"`
+	Kind        Kind   `json:"kind"`
+	Old         any    `json:"old,omitempty"`
+	New         any    `json:"new,omitempty"`
+	Description string `json:"description"`
+}
+
+// Breaking reports whether the change can reject values that were accepted
+func (c Change) Breaking() bool {
+	return c.Kind != Widening
+}
+
+// Diff compares two schemas node by node and classifies every difference
+func Diff(old, new *schema.Schema) []Change {
+	changes := []Change{}
+	diffNode("", old, new, &changes)
+	return changes
+}
+
+func diffNode(path string, old, new *schema.Schema, changes *[]Change) {
+	add := func(keyword string, kind Kind, o, n any, format string, args ...any) {
+		*changes = append(*changes, Change{
+			Path:        path + "/" + keyword,
+			Keyword:     keyword,
+			Kind:        kind,
+			Old:         o,
+			New:         n,
+			Description: fmt.Sprintf(format, args...),
+		})
+	}
+
+	diffType(old.Type, new.Type, add)
+	diffBound("minimum", old.Minimum, new.Minimum, false, add)
+	diffBound("maximum", old.Maximum, new.Maximum, true, add)
+	diffBound("exclusiveMinimum", old.ExclusiveMinimum, new.ExclusiveMinimum, false, add)
+	diffBound("exclusiveMaximum", old.ExclusiveMaximum, new.ExclusiveMaximum, true, add)
+	diffEnum(old.Enum, new.Enum, add)
+	diffRequired(old.Required, new.Required, add)
+
+	if severityName(old.Severity) != severityName(new.Severity) {
+		kind := Narrowing
+		if new.Severity == schema.SeverityWarning {
+			kind = Widening
+		}
+		add("x-severity", kind, old.Severity, new.Severity, "severity changed from <%s> to <%s>", severityName(old.Severity), severityName(new.Severity))
+	}
+
+	// deprecation changes what is reported, never what is accepted. the
+	// other annotations have no effect on validation and are not diffed
+	if old.Deprecated != new.Deprecated {
+		add("deprecated", Widening, old.Deprecated, new.Deprecated, "deprecated changed from <%t> to <%t>", old.Deprecated, new.Deprecated)
+	}
+
+	// anyOf branches are ordered and can overlap, so rather than guessing
+	// any change to them is flagged for review
+	if !reflect.DeepEqual(old.AnyOf, new.AnyOf) {
+		add("anyOf", Incompatible, old.AnyOf, new.AnyOf, "anyOf branches changed")
+	}
+	// items shift between prefixItems and items as entries are added or
+	// removed, so changes to them are flagged the same way
+	if !reflect.DeepEqual(old.PrefixItems, new.PrefixItems) {
+		add("prefixItems", Incompatible, old.PrefixItems, new.PrefixItems, "prefixItems changed")
+	}
+
+	// keys matched to properties change with aliases and folding rules,
+	// values of keys no longer matched are accepted as is while newly
+	// matched ones are validated
+	if !reflect.DeepEqual(old.Aliases, new.Aliases) {
+		add("x-aliases", Incompatible, old.Aliases, new.Aliases, "aliases changed from <%v> to <%v>", old.Aliases, new.Aliases)
+	}
+	if !reflect.DeepEqual(old.KeyFolding, new.KeyFolding) {
+		add("x-key-folding", Incompatible, old.KeyFolding, new.KeyFolding, "key folding changed from <%v> to <%v>", old.KeyFolding, new.KeyFolding)
+	}
+
+	// transforms change the values checked and kept, flag them for review
+	if !reflect.DeepEqual(old.Transforms, new.Transforms) {
+		add("x-transform", Incompatible, old.Transforms, new.Transforms, "transforms changed from <%v> to <%v>", old.Transforms, new.Transforms)
+	}
+
+	// converted values change with units, flag them for review
+	if old.Unit != new.Unit || old.UnitFrom != new.UnitFrom || old.SourceUnit != new.SourceUnit {
+		add("x-unit", Incompatible, []string{old.Unit, old.UnitFrom, old.SourceUnit}, []string{new.Unit, new.UnitFrom, new.SourceUnit}, "units changed from <%s> to <%s>", unitName(old), unitName(new))
+	}
+
+	if unmappedName(old.Unmapped) != unmappedName(new.Unmapped) {
+		// only reject fails listings, the other policies change where
+		// unmapped keys end up
+		kind := Incompatible
+		switch {
+		case new.Unmapped == schema.UnmappedReject:
+			kind = Narrowing
+		case old.Unmapped == schema.UnmappedReject:
+			kind = Widening
+		}
+		add("x-unmapped", kind, old.Unmapped, new.Unmapped, "unmapped policy changed from <%s> to <%s>", unmappedName(old.Unmapped), unmappedName(new.Unmapped))
+	}
+
+	// refs are compared by target rather than followed, a retargeted ref
+	// is flagged for review like anyOf. what an unchanged ref points to is
+	// diffed where it is defined, in $defs or in place
+	if old.Ref != new.Ref {
+		add("$ref", Incompatible, old.Ref, new.Ref, "$ref changed from <%s> to <%s>", old.Ref, new.Ref)
+	}
+	for _, k := range unionKeys(old.Defs, new.Defs) {
+		defPath := path + "/$defs/" + escapePointer(k)
+		o, inOld := old.Defs[k]
+		n, inNew := new.Defs[k]
+		switch {
+		case !inOld:
+			// a schema only takes effect through a ref, which is a change
+			// of its own
+			*changes = append(*changes, Change{Path: defPath, Keyword: "$defs", Kind: Widening, New: n, Description: fmt.Sprintf("definition <%s> added", k)})
+		case !inNew:
+			*changes = append(*changes, Change{Path: defPath, Keyword: "$defs", Kind: Widening, Old: o, Description: fmt.Sprintf("definition <%s> removed", k)})
+		case o != nil && n != nil:
+			diffNode(defPath, o, n, changes)
+		}
+	}
+
+	switch {
+	case old.AdditionalProperties == nil && new.AdditionalProperties != nil:
+		add("additionalProperties", Narrowing, nil, new.AdditionalProperties, "additional properties are now constrained")
+	case old.AdditionalProperties != nil && new.AdditionalProperties == nil:
+		add("additionalProperties", Widening, old.AdditionalProperties, nil, "additional properties are no longer constrained")
+	case old.AdditionalProperties != nil && new.AdditionalProperties != nil:
+		diffNode(path+"/additionalProperties", old.AdditionalProperties, new.AdditionalProperties, changes)
+	}
+
+	switch {
+	case old.PropertyNames == nil && new.PropertyNames != nil:
+		add("propertyNames", Narrowing, nil, new.PropertyNames, "property names are now constrained")
+	case old.PropertyNames != nil && new.PropertyNames == nil:
+		add("propertyNames", Widening, old.PropertyNames, nil, "property names are no longer constrained")
+	case old.PropertyNames != nil && new.PropertyNames != nil:
+		diffNode(path+"/propertyNames", old.PropertyNames, new.PropertyNames, changes)
+	}
+
+	for _, k := range unionKeys(old.PatternProperties, new.PatternProperties) {
+		patternPath := path + "/patternProperties/" + escapePointer(k)
+		o, inOld := old.PatternProperties[k]
+		n, inNew := new.PatternProperties[k]
+		switch {
+		case !inOld:
+			*changes = append(*changes, Change{Path: patternPath, Keyword: "patternProperties", Kind: Narrowing, New: n, Description: fmt.Sprintf("pattern <%s> added, values of matching keys were previously unconstrained", k)})
+		case !inNew:
+			*changes = append(*changes, Change{Path: patternPath, Keyword: "patternProperties", Kind: Widening, Old: o, Description: fmt.Sprintf("pattern <%s> removed, values of matching keys are now accepted as is", k)})
+		case o != nil && n != nil:
+			diffNode(patternPath, o, n, changes)
+		}
+	}
+
+	switch {
+	case old.Items == nil && new.Items != nil:
+		add("items", Narrowing, nil, new.Items, "array items are now constrained")
+	case old.Items != nil && new.Items == nil:
+		add("items", Widening, old.Items, nil, "array items are no longer constrained")
+	case old.Items != nil && new.Items != nil:
+		diffNode(path+"/items", old.Items, new.Items, changes)
+	}
+
+	oldProps := map[string]*schema.Schema{}
+	if old.Properties != nil {
+		oldProps = *old.Properties
+	}
+	newProps := map[string]*schema.Schema{}
+	if new.Properties != nil {
+		newProps = *new.Properties
+	}
+	for _, k := range unionKeys(oldProps, newProps) {
+		propPath := path + "/properties/" + escapePointer(k)
+		o, inOld := oldProps[k]
+		n, inNew := newProps[k]
+		switch {
+		case !inOld:
+			kind, description := addedProperty(k, old, n)
+			*changes = append(*changes, Change{Path: propPath, Keyword: "properties", Kind: kind, New: n, Description: description})
+		case !inNew:
+			kind, description := removedProperty(k, o, new)
+			*changes = append(*changes, Change{Path: propPath, Keyword: "properties", Kind: kind, Old: o, Description: description})
+		case o != nil && n != nil:
+			diffNode(propPath, o, n, changes)
+		}
+	}
+}
+
+// addedProperty classifies adding the property k of schema n to the
+// object schema old, by what its values went through before
+func addedProperty(k string, old, n *schema.Schema) (Kind, string) {
+	if _, _, ok := old.Schemas(k); ok {
+		return Narrowing, fmt.Sprintf("property <%s> added, its values were previously only validated by patternProperties", k)
+	}
+	if old.AdditionalProperties != nil && n != nil {
+		return propertyKind(old.AdditionalProperties, n), fmt.Sprintf("property <%s> added, its values were previously validated by additionalProperties", k)
+	}
+	if old.Unmapped == schema.UnmappedReject {
+		return Widening, fmt.Sprintf("property <%s> added, listings sending it were previously rejected as unmapped", k)
+	}
+	return Narrowing, fmt.Sprintf("property <%s> added, its values were previously unconstrained", k)
+}
+
+// removedProperty classifies removing the property k of schema o from the
+// object schema new, by what its values go through now
+func removedProperty(k string, o, new *schema.Schema) (Kind, string) {
+	if _, _, ok := new.Schemas(k); ok {
+		return Widening, fmt.Sprintf("property <%s> removed, its values are now only validated by patternProperties", k)
+	}
+	if new.AdditionalProperties != nil && o != nil {
+		return propertyKind(o, new.AdditionalProperties), fmt.Sprintf("property <%s> removed, its values are now validated by additionalProperties", k)
+	}
+	switch new.Unmapped {
+	case schema.UnmappedReject:
+		return Narrowing, fmt.Sprintf("property <%s> removed, listings sending it are now rejected as unmapped", k)
+	case schema.UnmappedDrop:
+		return Widening, fmt.Sprintf("property <%s> removed, its values are now dropped as unmapped", k)
+	case schema.UnmappedQuarantine:
+		return Widening, fmt.Sprintf("property <%s> removed, its values are now quarantined to extras", k)
+	}
+	return Widening, fmt.Sprintf("property <%s> removed, its values are now accepted as is", k)
+}
+
+// propertyKind classifies validating the values of a key against new
+// rather than old as a whole
+func propertyKind(old, new *schema.Schema) Kind {
+	changes := []Change{}
+	diffNode("", old, new, &changes)
+	kinds := map[Kind]bool{}
+	for _, c := range changes {
+		kinds[c.Kind] = true
+	}
+	switch {
+	case kinds[Incompatible] || (kinds[Narrowing] && kinds[Widening]):
+		return Incompatible
+	case kinds[Narrowing]:
+		return Narrowing
+	}
+	return Widening
+}
+
+func diffType(old, new schema.Type, add func(string, Kind, any, any, string, ...any)) {
+	oldCoversNew := len(old) == 0 || (len(new) > 0 && covers(old, new))
+	newCoversOld := len(new) == 0 || (len(old) > 0 && covers(new, old))
+	switch {
+	case oldCoversNew && newCoversOld:
+		return
+	case newCoversOld:
+		add("type", Widening, old, new, "type widened from <%v> to <%v>", typeName(old), typeName(new))
+	case oldCoversNew:
+		add("type", Narrowing, old, new, "type narrowed from <%v> to <%v>", typeName(old), typeName(new))
+	default:
+		add("type", Incompatible, old, new, "type changed from <%v> to <%v>", typeName(old), typeName(new))
+	}
+}
+
+// covers reports whether every type of sub is accepted by one of types.
+// integers are numbers, so number covers integer
+func covers(types, sub schema.Type) bool {
+	for _, t := range sub {
+		found := false
+		for _, u := range types {
+			if u == t || (u == "number" && t == "integer") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// diffBound compares a minimum, or a maximum when upper is set
+func diffBound(keyword string, old, new *float64, upper bool, add func(string, Kind, any, any, string, ...any)) {
+	switch {
+	case old == nil && new == nil:
+		return
+	case old == nil:
+		add(keyword, Narrowing, nil, *new, "%s of <%v> added", keyword, *new)
+	case new == nil:
+		add(keyword, Widening, *old, nil, "%s of <%v> removed", keyword, *old)
+	case *old == *new:
+		return
+	case (*new > *old) == upper:
+		add(keyword, Widening, *old, *new, "%s loosened from <%v> to <%v>", keyword, *old, *new)
+	default:
+		add(keyword, Narrowing, *old, *new, "%s tightened from <%v> to <%v>", keyword, *old, *new)
+	}
+}
+
+func diffEnum(old, new []any, add func(string, Kind, any, any, string, ...any)) {
+	switch {
+	case old == nil && new == nil:
+		return
+	case old == nil:
+		add("enum", Narrowing, nil, new, "enum <%v> added", new)
+		return
+	case new == nil:
+		add("enum", Widening, old, nil, "enum <%v> removed", old)
+		return
+	}
+
+	removed := missingFrom(old, new)
+	added := missingFrom(new, old)
+	switch {
+	case len(removed) == 0 && len(added) == 0:
+		return
+	case len(removed) == 0:
+		add("enum", Widening, old, new, "enum values <%v> added", added)
+	case len(added) == 0:
+		add("enum", Narrowing, old, new, "enum values <%v> removed", removed)
+	default:
+		add("enum", Incompatible, old, new, "enum values <%v> removed and <%v> added", removed, added)
+	}
+}
+
+// missingFrom returns the values of a not found in b
+func missingFrom(a, b []any) []any {
+	missing := []any{}
+	for _, av := range a {
+		found := false
+		for _, bv := range b {
+			if reflect.DeepEqual(av, bv) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, av)
+		}
+	}
+	return missing
+}
+
+func diffRequired(old, new []string, add func(string, Kind, any, any, string, ...any)) {
+	oldSet := map[string]bool{}
+	for _, k := range old {
+		oldSet[k] = true
+	}
+	newSet := map[string]bool{}
+	for _, k := range new {
+		newSet[k] = true
+	}
+	for _, k := range new {
+		if !oldSet[k] {
+			add("required", Narrowing, nil, k, "property <%s> is now required", k)
+		}
+	}
+	for _, k := range old {
+		if !newSet[k] {
+			add("required", Widening, k, nil, "property <%s> is no longer required", k)
+		}
+	}
+}
+
+func unionKeys(a, b map[string]*schema.Schema) []string {
+	keys := []string{}
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func typeName(t schema.Type) string {
+	if len(t) == 0 {
+		return "any"
+	}
+	return strings.Join(t, ", ")
+}
+
+func severityName(s string) string {
+	if s == "" {
+		return schema.SeverityError
+	}
+	return s
+}
+
+// unitName describes the unit keywords of s
+func unitName(s *schema.Schema) string {
+	if s.Unit == "" {
+		return "none"
+	}
+	from := s.SourceUnit
+	if s.UnitFrom != "" {
+		from = fmt.Sprintf("%s or key %s", from, s.UnitFrom)
+	}
+	if from == "" {
+		return s.Unit
+	}
+	return fmt.Sprintf("%s from %s", s.Unit, strings.TrimPrefix(from, " or "))
+}
+
+func unmappedName(s string) string {
+	if s == "" {
+		return schema.UnmappedAccept
+	}
+	return s
+}
+
+func escapePointer(k string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(k, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,198 @@
+package compat
+
+import (
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustSchema(t *testing.T, data string) *schema.Schema {
+	t.Helper()
+	s, err := schema.Load(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	return s
+}
+
+func TestDiff(t *testing.T) {
+	testCases := []struct {
+		description string
+		old         string
+		new         string
+		expected    []Change
+	}{
+		{
+			description: "it should classify number to integer as narrowing",
+			old:         `{ "properties": { "ListPrice": { "type": "number" } } }`,
+			new:         `{ "properties": { "ListPrice": { "type": "integer" } } }`,
+			expected: []Change{
+				{Path: "/properties/ListPrice/type", Keyword: "type", Kind: Narrowing},
+			},
+		},
+		{
+			description: "it should classify adding a type to a union as widening",
+			old:         `{ "properties": { "ListPrice": { "type": "number" } } }`,
+			new:         `{ "properties": { "ListPrice": { "type": ["number", "string"] } } }`,
+			expected: []Change{
+				{Path: "/properties/ListPrice/type", Keyword: "type", Kind: Widening},
+			},
+		},
+		{
+			description: "it should classify string to number as incompatible",
+			old:         `{ "properties": { "ListPrice": { "type": "string" } } }`,
+			new:         `{ "properties": { "ListPrice": { "type": "number" } } }`,
+			expected: []Change{
+				{Path: "/properties/ListPrice/type", Keyword: "type", Kind: Incompatible},
+			},
+		},
+		{
+			description: "it should classify new required properties and tighter ranges as narrowing",
+			old:         `{ "properties": { "Geo": { "type": "object", "properties": { "Lat": { "type": "number", "maximum": 100 } } } } }`,
+			new:         `{ "properties": { "Geo": { "type": "object", "required": ["Lat"], "properties": { "Lat": { "type": "number", "maximum": 90 } } } } }`,
+			expected: []Change{
+				{Path: "/properties/Geo/required", Keyword: "required", Kind: Narrowing},
+				{Path: "/properties/Geo/properties/Lat/maximum", Keyword: "maximum", Kind: Narrowing},
+			},
+		},
+		{
+			description: "it should classify removed properties as widening and added ones as narrowing",
+			old:         `{ "properties": { "Remarks": { "type": "string" } } }`,
+			new:         `{ "properties": { "Appliances": { "type": "array" } } }`,
+			expected: []Change{
+				{Path: "/properties/Appliances", Keyword: "properties", Kind: Narrowing},
+				{Path: "/properties/Remarks", Keyword: "properties", Kind: Widening},
+			},
+		},
+		{
+			description: "it should classify removed properties as narrowing when unmapped keys are rejected",
+			old:         `{ "x-unmapped": "reject", "properties": { "Remarks": { "type": "string" }, "ListPrice": { "type": "number" } } }`,
+			new:         `{ "x-unmapped": "reject", "properties": { "ListPrice": { "type": "number" }, "Appliances": { "type": "array" } } }`,
+			expected: []Change{
+				{Path: "/properties/Appliances", Keyword: "properties", Kind: Widening},
+				{Path: "/properties/Remarks", Keyword: "properties", Kind: Narrowing},
+			},
+		},
+		{
+			description: "it should classify removed properties by the additionalProperties now validating them",
+			old:         `{ "properties": { "Geo": { "type": "object", "additionalProperties": { "type": "string" }, "properties": { "Lat": { "type": "number" }, "County": { "type": "string" } } } } }`,
+			new:         `{ "properties": { "Geo": { "type": "object", "additionalProperties": { "type": "string" } } } }`,
+			expected: []Change{
+				{Path: "/properties/Geo/properties/County", Keyword: "properties", Kind: Widening},
+				{Path: "/properties/Geo/properties/Lat", Keyword: "properties", Kind: Incompatible},
+			},
+		},
+		{
+			description: "it should classify partially replaced enums as incompatible",
+			old:         `{ "properties": { "Status": { "enum": ["Active", "Closed"] } } }`,
+			new:         `{ "properties": { "Status": { "enum": ["Active", "Sold"] } } }`,
+			expected: []Change{
+				{Path: "/properties/Status/enum", Keyword: "enum", Kind: Incompatible},
+			},
+		},
+		{
+			description: "it should classify constraining additional properties as narrowing",
+			old:         `{ "properties": { "Geo": { "type": "object" } } }`,
+			new:         `{ "properties": { "Geo": { "type": "object", "additionalProperties": { "type": "string" } } } }`,
+			expected: []Change{
+				{Path: "/properties/Geo/additionalProperties", Keyword: "additionalProperties", Kind: Narrowing},
+			},
+		},
+		{
+			description: "it should diff patternProperties by pattern",
+			old:         `{ "patternProperties": { "^Room\\d+Level$": { "type": "string" } } }`,
+			new:         `{ "patternProperties": { "^Room\\d+Level$": { "type": "integer" }, "^Custom_": { "type": "string" } } }`,
+			expected: []Change{
+				{Path: "/patternProperties/^Custom_", Keyword: "patternProperties", Kind: Narrowing},
+				{Path: "/patternProperties/^Room\\d+Level$/type", Keyword: "type", Kind: Incompatible},
+			},
+		},
+		{
+			description: "it should classify rejecting unmapped keys as narrowing",
+			old:         `{ "properties": { "Remarks": { "type": "string" } } }`,
+			new:         `{ "x-unmapped": "reject", "properties": { "Remarks": { "type": "string" } } }`,
+			expected: []Change{
+				{Path: "/x-unmapped", Keyword: "x-unmapped", Kind: Narrowing},
+			},
+		},
+		{
+			description: "it should classify lowering an exclusive maximum as narrowing",
+			old:         `{ "properties": { "ListPrice": { "type": "number", "exclusiveMaximum": 100 } } }`,
+			new:         `{ "properties": { "ListPrice": { "type": "number", "exclusiveMaximum": 90 } } }`,
+			expected: []Change{
+				{Path: "/properties/ListPrice/exclusiveMaximum", Keyword: "exclusiveMaximum", Kind: Narrowing},
+			},
+		},
+		{
+			description: "it should not report a draft-04 schema moved to 2020-12",
+			old:         `{ "$schema": "http://json-schema.org/draft-04/schema#", "properties": { "ListPrice": { "type": "number", "maximum": 100, "exclusiveMaximum": true } } }`,
+			new:         `{ "$schema": "https://json-schema.org/draft/2020-12/schema", "properties": { "ListPrice": { "type": "number", "exclusiveMaximum": 100 } } }`,
+			expected:    []Change{},
+		},
+		{
+			description: "it should classify deprecating a property as widening",
+			old:         `{ "properties": { "LotSizeAcres": { "type": "number" } } }`,
+			new:         `{ "properties": { "LotSizeAcres": { "type": "number", "deprecated": true, "description": "use LotSizeArea" } } }`,
+			expected: []Change{
+				{Path: "/properties/LotSizeAcres/deprecated", Keyword: "deprecated", Kind: Widening},
+			},
+		},
+		{
+			description: "it should diff definitions behind an unchanged ref",
+			old:         `{ "$defs": { "p": { "type": "number" } }, "properties": { "ListPrice": { "$ref": "#/$defs/p" } } }`,
+			new:         `{ "$defs": { "p": { "type": "integer" } }, "properties": { "ListPrice": { "$ref": "#/$defs/p" } } }`,
+			expected: []Change{
+				{Path: "/$defs/p/type", Keyword: "type", Kind: Narrowing},
+			},
+		},
+		{
+			description: "it should diff draft-07 definitions as $defs",
+			old:         `{ "$schema": "http://json-schema.org/draft-07/schema#", "definitions": { "p": { "type": "number" } }, "properties": { "ListPrice": { "$ref": "#/definitions/p" } } }`,
+			new:         `{ "$schema": "http://json-schema.org/draft-07/schema#", "definitions": { "p": { "type": "string" } }, "properties": { "ListPrice": { "$ref": "#/definitions/p" } } }`,
+			expected: []Change{
+				{Path: "/$defs/p/type", Keyword: "type", Kind: Incompatible},
+			},
+		},
+		{
+			description: "it should not report identical schemas",
+			old:         `{ "properties": { "Status": { "type": "string", "x-severity": "error" } } }`,
+			new:         `{ "properties": { "Status": { "type": "string" } } }`,
+			expected:    []Change{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		changes := Diff(mustSchema(t, testCase.old), mustSchema(t, testCase.new))
+		// only compare the classification, not the values and descriptions
+		got := []Change{}
+		for _, c := range changes {
+			got = append(got, Change{Path: c.Path, Keyword: c.Keyword, Kind: c.Kind})
+		}
+		if !reflect.DeepEqual(got, testCase.expected) {
+			t.Fatalf("%s: changes <%+v> do not match expected <%+v>", testCase.description, got, testCase.expected)
+		}
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	old := mustSchema(t, `{ "properties": { "ListPrice": { "type": "number" }, "Remarks": { "type": "number" } } }`)
+	new := mustSchema(t, `{ "properties": { "ListPrice": { "type": "number", "maximum": 1000000 }, "Remarks": { "type": "string" } } }`)
+
+	rp := NewReplayer(old, new, false, 1)
+	rp.Add(approach_3.Listing{Data: []approach_3.KeyVal{
+		{Key: "ListPrice", Value: 500000.0},
+		{Key: "ListPrice", Value: 5000000.0},
+		{Key: "ListPrice", Value: 6000000.0},
+		{Key: "Remarks", Value: "nice"},
+	}})
+
+	expected := []KeyResult{
+		{Key: "ListPrice", Total: 3, NowRejected: 2, Samples: []any{5000000.0}},
+		{Key: "Remarks", Total: 1, NowAccepted: 1},
+	}
+	if results := rp.Results(); !reflect.DeepEqual(results, expected) {
+		t.Fatalf("results <%+v> do not match expected <%+v>", results, expected)
+	}
+}
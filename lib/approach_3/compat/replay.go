@@ -0,0 +1,85 @@
+package compat
+
+import (
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/report"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"sort"
+)
+
+// KeyResult is how the key:vals of a single key fared under the old and
+// the new schema
+type KeyResult struct {
+	Key string `json:"key"`
+	// Total is the number of key:vals replayed for the key
+	Total int `json:"total"`
+	// NowRejected counts key:vals accepted by the old schema only
+	NowRejected int `json:"now_rejected"`
+	// NowAccepted counts key:vals accepted by the new schema only
+	NowAccepted int `json:"now_accepted"`
+	// Samples holds a few of the values now rejected
+	Samples []any `json:"samples,omitempty"`
+}
+
+// Replayer runs a corpus of listings through two schemas to find the keys
+// whose values change from accepted to rejected, or the other way around
+type Replayer struct {
+	old, new   *schema.Schema
+	coerce     bool
+	maxSamples int
+	results    map[string]*KeyResult
+}
+
+func NewReplayer(old, new *schema.Schema, coerce bool, maxSamples int) *Replayer {
+	return &Replayer{
+		old:        old,
+		new:        new,
+		coerce:     coerce,
+		maxSamples: maxSamples,
+		results:    map[string]*KeyResult{},
+	}
+}
+
+var discard = approach_3.ReporterFunc(func(report.BadKeyVal) error { return nil })
+
+// Add replays every key:value of l through both schemas
+func (rp *Replayer) Add(l approach_3.Listing) {
+	for _, kv := range l.Data {
+		_, oldErr := kv.Validate(rp.old, discard, rp.coerce)
+		_, newErr := kv.Validate(rp.new, discard, rp.coerce)
+
+		res, ok := rp.results[kv.Key]
+		if !ok {
+			res = &KeyResult{Key: kv.Key}
+			rp.results[kv.Key] = res
+		}
+		res.Total++
+		switch {
+		case oldErr == nil && newErr != nil:
+			res.NowRejected++
+			if len(res.Samples) < rp.maxSamples {
+				res.Samples = append(res.Samples, kv.Value)
+			}
+		case oldErr != nil && newErr == nil:
+			res.NowAccepted++
+		}
+	}
+}
+
+// Results returns the keys whose outcome changed for at least one value,
+// the most newly rejected first
+func (rp *Replayer) Results() []KeyResult {
+	out := []KeyResult{}
+	for _, res := range rp.results {
+		if res.NowRejected > 0 || res.NowAccepted > 0 {
+			out = append(out, *res)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].NowRejected != out[j].NowRejected {
+			return out[i].NowRejected > out[j].NowRejected
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
@@ -0,0 +1,209 @@
+package deadletter
+
+import (
+	"bufio"
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/overlay"
+	"cmenke/go-playground/lib/approach_3/report"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry is a single rejected key:value as persisted in a dead-letter file,
+// one json object per line
+type Entry struct {
+	Time          time.Time `json:"time"`
+	DocId         string    `json:"docid"`
+	Mls           string    `json:"mls"`
+	SchemaVersion string    `json:"schema_version,omitempty"`
+	Key           string    `json:"key"`
+	Value         any       `json:"value"`
+	Error         string    `json:"error"`
+}
+
+// Writer is a Reporter appending every reported BadKeyVal to an ndjson
+// dead-letter log so it can be replayed once the schema is fixed. it is
+// safe for concurrent use
+type Writer struct {
+	mu            sync.Mutex
+	w             io.Writer
+	closer        io.Closer
+	schemaVersion string
+	now           func() time.Time
+}
+
+// Open opens, or creates, the append-only dead-letter file at path.
+// schemaVersion is recorded on every entry to know which schema
+// rejected it, unless the report carries its own schema version
+func Open(path string, schemaVersion string) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead-letter file <%s>: %w", path, err)
+	}
+	dl := NewWriter(f, schemaVersion)
+	dl.closer = f
+	return dl, nil
+}
+
+// NewWriter returns a Writer appending entries to w
+func NewWriter(w io.Writer, schemaVersion string) *Writer {
+	return &Writer{
+		w:             w,
+		schemaVersion: schemaVersion,
+		now:           time.Now,
+	}
+}
+
+func (dl *Writer) Report(e report.BadKeyVal) error {
+	// warned about values were kept, there is nothing to replay
+	if e.Severity == report.SeverityWarning {
+		return nil
+	}
+
+	version := e.SchemaVersion
+	if version == "" {
+		version = dl.schemaVersion
+	}
+	entry := Entry{
+		Time:          dl.now().UTC(),
+		DocId:         e.DocId,
+		Mls:           e.Mls,
+		SchemaVersion: version,
+		Key:           e.Key,
+		Value:         e.Value,
+	}
+	if e.Error != nil {
+		entry.Error = e.Error.Error()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead-letter entry for key <%s>: %w", e.Key, err)
+	}
+	line = append(line, '\n')
+
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	// write the whole line at once so concurrent reports never interleave
+	if _, err := dl.w.Write(line); err != nil {
+		return fmt.Errorf("failed to write dead-letter entry for key <%s>: %w", e.Key, err)
+	}
+	return nil
+}
+
+// Close closes the underlying file if the Writer was created with Open
+func (dl *Writer) Close() error {
+	if dl.closer == nil {
+		return nil
+	}
+	return dl.closer.Close()
+}
+
+// Replay reads dead-letter entries from r and re-validates them against
+// the effective schema of their mls from resolver. the entries of a
+// listing are replayed together through Listing.Validate, so keywords
+// reading sibling keys, such as `x-unit-from`, see the other entries of
+// the listing. entries that now pass are handed to emit along with their
+// validated, possibly coerced, key:value. entries that still fail, are
+// dropped or quarantined, or belong to a listing rejected as a whole are
+// skipped
+func Replay(r io.Reader, resolver *overlay.Resolver, coerce bool, emit func(Entry, approach_3.KeyVal) error) error {
+	type listingKey struct{ mls, docId string }
+	// entries are grouped by listing in the order listings are first
+	// seen, concurrent writers may have interleaved them
+	order := []listingKey{}
+	listings := map[listingKey][]Entry{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("failed to unmarshal dead-letter entry on line %d: %w", line, err)
+		}
+		k := listingKey{entry.Mls, entry.DocId}
+		if _, ok := listings[k]; !ok {
+			order = append(order, k)
+		}
+		listings[k] = append(listings[k], entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, k := range order {
+		if err := replayListing(listings[k], resolver, coerce, emit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outcomes is a Reporter discarding reports and recording the result of
+// every validated key:value in order
+type outcomes struct {
+	results []report.Result
+}
+
+func (o *outcomes) Report(report.BadKeyVal) error { return nil }
+
+func (o *outcomes) Observe(out report.Outcome) {
+	o.results = append(o.results, out.Result)
+}
+
+// replayListing replays the entries of a single listing
+func replayListing(entries []Entry, resolver *overlay.Resolver, coerce bool, emit func(Entry, approach_3.KeyVal) error) error {
+	s, err := resolver.For(entries[0].Mls)
+	if err != nil {
+		return err
+	}
+	l := approach_3.Listing{DocId: entries[0].DocId, Mls: entries[0].Mls}
+	for _, entry := range entries {
+		l.Data = append(l.Data, approach_3.KeyVal{Key: entry.Key, Value: entry.Value})
+	}
+	o := &outcomes{}
+	_ = l.Validate(s, o, coerce)
+	if l.Rejected {
+		return nil
+	}
+
+	// l.Data holds the key:values that were kept, in the order they
+	// were sent, every outcome but these is one that was removed
+	kept := 0
+	for i, entry := range entries {
+		switch o.results[i] {
+		case report.ResultRejected:
+			continue
+		case report.ResultUnmapped:
+			if s.Unmapped == schema.UnmappedDrop || s.Unmapped == schema.UnmappedQuarantine {
+				continue
+			}
+		}
+		if err := emit(entry, l.Data[kept]); err != nil {
+			return err
+		}
+		kept++
+	}
+	return nil
+}
+
+// ReplayFile is Replay reading from the dead-letter file at path
+func ReplayFile(path string, resolver *overlay.Resolver, coerce bool, emit func(Entry, approach_3.KeyVal) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file <%s>: %w", path, err)
+	}
+	defer f.Close()
+	return Replay(f, resolver, coerce, emit)
+}
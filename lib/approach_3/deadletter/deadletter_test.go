@@ -0,0 +1,120 @@
+package deadletter
+
+import (
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/overlay"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustSchema(t *testing.T, data string) *schema.Schema {
+	t.Helper()
+	var s schema.Schema
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		t.Fatalf("failed to unmarshal schema: %s", err)
+	}
+	return &s
+}
+
+func TestDeadLetterReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rejected.ndjson")
+
+	oldSchema := mustSchema(t, `{
+		"properties": {
+			"ListPrice": { "type": "integer" },
+			"Remarks": { "type": "number" }
+		}
+	}`)
+	newSchema := mustSchema(t, `{
+		"properties": {
+			"ListPrice": { "type": "number" },
+			"Remarks": { "type": "number" }
+		}
+	}`)
+
+	dl, err := Open(path, "v1")
+	if err != nil {
+		t.Fatalf("failed to open dead-letter file: %s", err)
+	}
+	l := approach_3.Listing{
+		DocId: "1234",
+		Mls:   "rets-properties-test",
+		Data: []approach_3.KeyVal{
+			{Key: "ListPrice", Value: 100000.10},
+			{Key: "Remarks", Value: "still not a number"},
+		},
+	}
+	_ = l.Validate(oldSchema, dl, false)
+	if err := dl.Close(); err != nil {
+		t.Fatalf("failed to close dead-letter file: %s", err)
+	}
+
+	var entries []Entry
+	var replayed []approach_3.KeyVal
+	err = ReplayFile(path, overlay.NewResolver(newSchema, nil), false, func(e Entry, kv approach_3.KeyVal) error {
+		entries = append(entries, e)
+		replayed = append(replayed, kv)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to replay dead-letter file: %s", err)
+	}
+
+	expected := []approach_3.KeyVal{{Key: "ListPrice", Value: 100000.10}}
+	if !reflect.DeepEqual(replayed, expected) {
+		t.Fatalf("replayed <%v> does not match expected <%v>", replayed, expected)
+	}
+	if entries[0].DocId != "1234" || entries[0].Mls != "rets-properties-test" || entries[0].SchemaVersion != "v1" || entries[0].Error == "" {
+		t.Fatalf("replayed entry is missing listing details: %+v", entries[0])
+	}
+}
+
+func TestDeadLetterReplayListings(t *testing.T) {
+	base, err := schema.Load(strings.NewReader(`{
+		"properties": {
+			"ListPrice": { "type": "integer" },
+			"LotSize": { "type": "number", "x-unit": "sqft", "x-unit-from": "LotSizeUnits" },
+			"LotSizeUnits": { "type": "string" }
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	resolver := overlay.NewResolver(base, map[string][]byte{
+		"canada": []byte(`{ "properties": { "ListPrice": { "type": "number" } } }`),
+	})
+
+	// the entries of listing 1 are interleaved with the ones of listing 2
+	deadLetters := strings.Join([]string{
+		`{"docid":"1","mls":"canada","key":"ListPrice","value":100.5}`,
+		`{"docid":"1","mls":"canada","key":"LotSize","value":0.5}`,
+		`{"docid":"2","mls":"other","key":"ListPrice","value":100.5}`,
+		`{"docid":"1","mls":"canada","key":"LotSizeUnits","value":"acres"}`,
+	}, "\n")
+
+	replayed := []approach_3.KeyVal{}
+	err = Replay(strings.NewReader(deadLetters), resolver, false, func(e Entry, kv approach_3.KeyVal) error {
+		if e.DocId != "1" {
+			t.Fatalf("unexpected entry of listing <%s> replayed", e.DocId)
+		}
+		replayed = append(replayed, kv)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to replay dead-letter entries: %s", err)
+	}
+
+	// the overlay of the mls applies and the sibling unit is found
+	expected := []approach_3.KeyVal{
+		{Key: "ListPrice", Value: 100.5},
+		{Key: "LotSize", Value: 21780.0},
+		{Key: "LotSizeUnits", Value: "acres"},
+	}
+	if !reflect.DeepEqual(replayed, expected) {
+		t.Fatalf("replayed <%v> does not match expected <%v>", replayed, expected)
+	}
+}
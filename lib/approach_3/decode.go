@@ -0,0 +1,286 @@
+package approach_3
+
+import (
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// FieldError is a listing value Decode could not assign to its field
+type FieldError struct {
+	Field string // path of the field in the destination, such as Geo[0].Timezone
+	Key   string // listing key the value came from
+	Value any
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field <%s> key <%s> with value <%v>: %s", e.Field, e.Key, e.Value, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Decode assigns the values of a validated listing to the fields of dst, a
+// pointer to a struct. fields are matched to keys by their `listing` tag,
+// or their name when untagged, `listing:"-"` skips a field. nested objects
+// decode into structs or maps and arrays into slices the same way.
+//
+// s is the schema the listing was validated against, values are only
+// converted between the types it allows, such as a whole number into an
+// int field. fields that cannot be assigned, or hold a slice, map or
+// pointer to a value that cannot, are left untouched and returned as
+// joined *FieldError. keys without a field are ignored and when a key
+// appears more than once the last value wins
+func (l *Listing) Decode(s *schema.Schema, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode destination must be a non nil pointer to a struct, got <%T>", dst)
+	}
+
+	obj := make(map[string]any, len(l.Data))
+	for _, kv := range l.Data {
+		obj[kv.Key] = kv.Value
+	}
+	d := &decoder{}
+	d.decodeStruct(obj, s, rv.Elem(), "", "")
+	return errors.Join(d.errs...)
+}
+
+type decoder struct {
+	errs []error
+}
+
+func (d *decoder) fail(field, key string, val any, format string, args ...any) {
+	d.errs = append(d.errs, &FieldError{Field: field, Key: key, Value: val, Err: fmt.Errorf(format, args...)})
+}
+
+var textUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// decode assigns val, validated against s, to dst. field and key locate
+// dst for errors
+func (d *decoder) decode(val any, s *schema.Schema, dst reflect.Value, field, key string) {
+	if err := checkType(s, dst.Type()); err != nil {
+		d.fail(field, key, val, "%s", err)
+		return
+	}
+
+	if val == nil {
+		switch dst.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			dst.SetZero()
+		default:
+			d.fail(field, key, val, "null cannot be assigned to a field of type <%s>", dst.Type())
+		}
+		return
+	}
+
+	if dst.Kind() == reflect.Pointer {
+		// decode into a new value so a failure leaves the field untouched
+		elem := reflect.New(dst.Type().Elem())
+		before := len(d.errs)
+		d.decode(val, s, elem.Elem(), field, key)
+		if len(d.errs) == before {
+			dst.Set(elem)
+		}
+		return
+	}
+
+	if str, ok := val.(string); ok && reflect.PointerTo(dst.Type()).Implements(textUnmarshaler) {
+		if err := dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(str)); err != nil {
+			d.fail(field, key, val, "%s", err)
+		}
+		return
+	}
+
+	switch dst.Kind() {
+	case reflect.Interface:
+		if !reflect.TypeOf(val).AssignableTo(dst.Type()) {
+			d.fail(field, key, val, "value of type <%T> cannot be assigned to a field of type <%s>", val, dst.Type())
+			return
+		}
+		dst.Set(reflect.ValueOf(val))
+	case reflect.Bool:
+		b, ok := val.(bool)
+		if !ok {
+			d.fail(field, key, val, "value of type <%T> cannot be assigned to a bool field", val)
+			return
+		}
+		dst.SetBool(b)
+	case reflect.String:
+		str, ok := val.(string)
+		if !ok {
+			d.fail(field, key, val, "value of type <%T> cannot be assigned to a string field", val)
+			return
+		}
+		dst.SetString(str)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := number(val)
+		if !ok || n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 || dst.OverflowInt(int64(n)) {
+			d.fail(field, key, val, "value cannot be assigned to a field of type <%s> without loss", dst.Type())
+			return
+		}
+		dst.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := number(val)
+		if !ok || n != math.Trunc(n) || n < 0 || n >= math.MaxUint64 || dst.OverflowUint(uint64(n)) {
+			d.fail(field, key, val, "value cannot be assigned to a field of type <%s> without loss", dst.Type())
+			return
+		}
+		dst.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		n, ok := number(val)
+		if !ok || dst.OverflowFloat(n) {
+			d.fail(field, key, val, "value cannot be assigned to a field of type <%s> without loss", dst.Type())
+			return
+		}
+		dst.SetFloat(n)
+	case reflect.Slice:
+		items, ok := val.([]any)
+		if !ok {
+			d.fail(field, key, val, "value of type <%T> cannot be assigned to a slice field", val)
+			return
+		}
+		var itemSchema *schema.Schema
+		if s != nil {
+			itemSchema = s.Items
+		}
+		out := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		before := len(d.errs)
+		for i, item := range items {
+			d.decode(item, itemSchema, out.Index(i), fmt.Sprintf("%s[%d]", field, i), key)
+		}
+		if len(d.errs) == before {
+			dst.Set(out)
+		}
+	case reflect.Map:
+		obj, ok := val.(map[string]any)
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			d.fail(field, key, val, "value of type <%T> cannot be assigned to a field of type <%s>", val, dst.Type())
+			return
+		}
+		out := reflect.MakeMapWithSize(dst.Type(), len(obj))
+		before := len(d.errs)
+		for k, v := range obj {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			d.decode(v, propertySchema(s, k), elem, fmt.Sprintf("%s[%q]", field, k), key)
+			out.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), elem)
+		}
+		if len(d.errs) == before {
+			dst.Set(out)
+		}
+	case reflect.Struct:
+		obj, ok := val.(map[string]any)
+		if !ok {
+			d.fail(field, key, val, "value of type <%T> cannot be assigned to a struct field", val)
+			return
+		}
+		d.decodeStruct(obj, s, dst, field, key)
+	default:
+		d.fail(field, key, val, "fields of type <%s> are not supported", dst.Type())
+	}
+}
+
+// decodeStruct assigns the values of obj to the fields of dst. key is the
+// listing key of obj, empty for the listing itself
+func (d *decoder) decodeStruct(obj map[string]any, s *schema.Schema, dst reflect.Value, field, key string) {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("listing"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		val, ok := obj[name]
+		if !ok {
+			continue
+		}
+
+		fieldPath := f.Name
+		if field != "" {
+			fieldPath = field + "." + f.Name
+		}
+		valKey := key
+		if valKey == "" {
+			valKey = name
+		}
+		d.decode(val, propertySchema(s, name), dst.Field(i), fieldPath, valKey)
+	}
+}
+
+// propertySchema returns the schema of property k of s, if any
+func propertySchema(s *schema.Schema, k string) *schema.Schema {
+	if s == nil {
+		return nil
+	}
+	if s.Properties != nil {
+		if ps, ok := (*s.Properties)[k]; ok {
+			return ps
+		}
+	}
+	return s.AdditionalProperties
+}
+
+// checkType fails when no type allowed by s can be held by fields of type
+// t, such as a string schema decoded into an int field
+func checkType(s *schema.Schema, t reflect.Type) error {
+	if s == nil || len(s.Type) == 0 {
+		return nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface || reflect.PointerTo(t).Implements(textUnmarshaler) {
+		return nil
+	}
+	for _, st := range s.Type {
+		if typeHolds(st, t.Kind()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("a field of type <%s> cannot hold schema type(s) <%s>", t, strings.Join(s.Type, ", "))
+}
+
+func typeHolds(schemaType string, k reflect.Kind) bool {
+	switch schemaType {
+	case "boolean":
+		return k == reflect.Bool
+	case "string":
+		return k == reflect.String
+	case "integer", "number":
+		return k >= reflect.Int && k <= reflect.Float64
+	case "array":
+		return k == reflect.Slice
+	case "object":
+		return k == reflect.Struct || k == reflect.Map
+	}
+	return false
+}
+
+// number returns val as a float64 if it is numeric, like the coerced int
+// of a stringified number
+func number(val any) (float64, bool) {
+	rv := reflect.ValueOf(val)
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int()), true
+	case rv.CanUint():
+		return float64(rv.Uint()), true
+	case rv.CanFloat():
+		return rv.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,153 @@
+package approach_3
+
+import (
+	"cmenke/go-playground/lib/approach_3/schema"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type timezone struct {
+	Name        string `listing:"Name"`
+	ObservesDLS *bool  `listing:"ObservesDLS"`
+}
+
+type geo struct {
+	Identifier string    `listing:"identifier"`
+	Timezone   *timezone `listing:"Timezone"`
+}
+
+type decodedListing struct {
+	ListPrice  float64   `listing:"ListPrice"`
+	Beds       int       `listing:"Beds"`
+	Appliances []string  `listing:"Appliances"`
+	Geo        []geo     `listing:"Geo"`
+	Listed     time.Time `listing:"ListingContractDate"`
+	Remarks    *string
+	Ignored    string `listing:"-"`
+}
+
+const decodeSchema = `{
+	"properties": {
+		"ListPrice": { "type": "number" },
+		"Beds": { "type": "number" },
+		"Appliances": { "type": "array", "items": { "type": "string" } },
+		"ListingContractDate": { "type": "string" },
+		"Remarks": { "type": ["string", "null"] },
+		"Geo": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"properties": {
+					"identifier": { "type": "string" },
+					"Timezone": { "type": "object", "properties": { "ObservesDLS": { "type": "boolean" } } }
+				}
+			}
+		}
+	}
+}`
+
+func TestListingDecode(t *testing.T) {
+	s, err := schema.Load(strings.NewReader(decodeSchema))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	yes := true
+	remarks := "nice"
+
+	testCases := []struct {
+		description    string
+		data           []KeyVal
+		expected       decodedListing
+		expectedFields []string // fields reported as not assigned
+	}{
+		{
+			description: "it should decode validated values into their fields",
+			data: []KeyVal{
+				{Key: "ListPrice", Value: 100000}, // coerced from a string into an int
+				{Key: "Beds", Value: 3.0},
+				{Key: "Appliances", Value: []any{"oven", "fridge"}},
+				{Key: "ListingContractDate", Value: "2024-01-02T00:00:00Z"},
+				{Key: "Remarks", Value: "nice"},
+				{Key: "Ignored", Value: "x"},
+				{Key: "Unmapped", Value: "x"},
+			},
+			expected: decodedListing{
+				ListPrice:  100000,
+				Beds:       3,
+				Appliances: []string{"oven", "fridge"},
+				Listed:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+				Remarks:    &remarks,
+			},
+		},
+		{
+			description: "it should decode nested objects into slices of structs",
+			data: []KeyVal{
+				{Key: "Geo", Value: []any{map[string]any{"identifier": "GeoNSRF", "Timezone": map[string]any{"Name": "America/Los_Angeles", "ObservesDLS": true}}}},
+			},
+			expected: decodedListing{
+				Geo: []geo{{Identifier: "GeoNSRF", Timezone: &timezone{Name: "America/Los_Angeles", ObservesDLS: &yes}}},
+			},
+		},
+		{
+			description: "it should report values that cannot be assigned without loss",
+			data: []KeyVal{
+				{Key: "ListPrice", Value: 1.0},
+				{Key: "Beds", Value: 2.5},
+				{Key: "Appliances", Value: []any{"oven", 1.0}},
+				{Key: "Geo", Value: []any{map[string]any{"Timezone": map[string]any{"ObservesDLS": "yes"}}}},
+			},
+			expected:       decodedListing{ListPrice: 1},
+			expectedFields: []string{"Beds", "Appliances[1]", "Geo[0].Timezone.ObservesDLS"},
+		},
+		{
+			description: "it should set nullable fields to nil",
+			data:        []KeyVal{{Key: "Remarks", Value: nil}},
+			expected:    decodedListing{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		l := Listing{DocId: "1", Mls: "test", Data: testCase.data}
+		var got decodedListing
+		err := l.Decode(s, &got)
+
+		fields := []string{}
+		if err != nil {
+			for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+				var fe *FieldError
+				if !errors.As(e, &fe) {
+					t.Fatalf("%s: expected a FieldError, got <%v>", testCase.description, e)
+				}
+				fields = append(fields, fe.Field)
+			}
+		}
+		if len(fields) != len(testCase.expectedFields) || (len(fields) > 0 && !reflect.DeepEqual(fields, testCase.expectedFields)) {
+			t.Fatalf("%s: expected field errors <%v>, got <%v>", testCase.description, testCase.expectedFields, err)
+		}
+		if !reflect.DeepEqual(got, testCase.expected) {
+			t.Fatalf("%s: expected <%+v>, got <%+v>", testCase.description, testCase.expected, got)
+		}
+	}
+}
+
+func TestListingDecodeChecksSchemaTypes(t *testing.T) {
+	s, err := schema.Load(strings.NewReader(`{ "properties": { "Remarks": { "type": "string" } } }`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	var dst struct {
+		Remarks int
+	}
+	l := Listing{Data: []KeyVal{{Key: "Remarks", Value: "1"}}}
+	var fe *FieldError
+	if err := l.Decode(s, &dst); !errors.As(err, &fe) || fe.Key != "Remarks" || !strings.Contains(fe.Error(), "cannot hold schema type(s) <string>") {
+		t.Fatalf("expected an int field to be rejected for a string schema, got <%v>", err)
+	}
+
+	if err := l.Decode(s, dst); err == nil {
+		t.Fatalf("expected a non pointer destination to fail")
+	}
+}
@@ -0,0 +1,229 @@
+package approach_3
+
+import (
+	"cmenke/go-playground/lib/approach_3/report"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// DuplicatePolicy decides what ToDocument does with a key appearing more
+// than once in a listing
+type DuplicatePolicy string
+
+const (
+	// DuplicateReject fails the conversion, it is the default
+	DuplicateReject DuplicatePolicy = "reject"
+	// DuplicateFirst keeps the first value of the key
+	DuplicateFirst DuplicatePolicy = "first"
+	// DuplicateLast keeps the last value of the key
+	DuplicateLast DuplicatePolicy = "last"
+	// DuplicateCollect keeps every value of the key, in order, in an array
+	DuplicateCollect DuplicatePolicy = "collect"
+)
+
+// ToDocument returns the key:values of the listing as a flat document
+// keyed by key. listings without repeated keys convert losslessly, back
+// and forth with FromDocument, repeated keys are handled by policy
+func (l *Listing) ToDocument(policy DuplicatePolicy) (map[string]any, error) {
+	doc := make(map[string]any, len(l.Data))
+	// keys already collected into an array by DuplicateCollect
+	collected := map[string]bool{}
+	for _, kv := range l.Data {
+		prev, seen := doc[kv.Key]
+		if !seen {
+			doc[kv.Key] = kv.Value
+			continue
+		}
+		switch policy {
+		case DuplicateFirst:
+			// the value already there is the first
+		case DuplicateLast:
+			doc[kv.Key] = kv.Value
+		case DuplicateCollect:
+			if !collected[kv.Key] {
+				prev = []any{prev}
+				collected[kv.Key] = true
+			}
+			doc[kv.Key] = append(prev.([]any), kv.Value)
+		case DuplicateReject, "":
+			return nil, fmt.Errorf("key <%s>: %w, it appears more than once", kv.Key, schema.ErrDuplicateKey)
+		default:
+			return nil, fmt.Errorf("unknown duplicate policy <%s>", policy)
+		}
+	}
+	return doc, nil
+}
+
+// FromDocument returns the key:values of a flat document, ordered by key
+func FromDocument(doc map[string]any) []KeyVal {
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	data := make([]KeyVal, 0, len(keys))
+	for _, k := range keys {
+		data = append(data, KeyVal{Key: k, Value: doc[k]})
+	}
+	return data
+}
+
+// ValidateDocument validates the listing as a single flat document with
+// one Eval of s, so object keywords such as required and propertyNames
+// apply across its keys. repeated keys are handled by policy. like
+// Validate, failing keys are reported to r, each with the severity of
+// its own failure, and removed from l.Data, which ends up ordered by key.
+// failures not tied to a key, such as a missing required key, are
+// reported without a key. keys matching no property, pattern or
+// additionalProperties are handled by the x-unmapped policy of s, and
+// outcomes are sent to r if it is an Observer
+func (l *Listing) ValidateDocument(s *schema.Schema, r Reporter, coerce bool, policy DuplicatePolicy) error {
+	if s == nil {
+		return errors.New("schema is nil, cannot validate document")
+	}
+	start := time.Now()
+	l.SchemaVersion = s.Version
+	bad := report.BadKeyVal{DocId: l.DocId, Mls: l.Mls, SchemaVersion: s.Version, Event: report.EventInvalid}
+	observe := func(key string, res report.Result) {
+		if o, ok := r.(Observer); ok {
+			o.Observe(report.Outcome{
+				DocId:         l.DocId,
+				Mls:           l.Mls,
+				Key:           key,
+				Result:        res,
+				Duration:      time.Since(start),
+				SchemaVersion: s.Version,
+			})
+		}
+	}
+
+	doc, err := l.ToDocument(policy)
+	if err != nil {
+		bad.Error = err
+		bad.Severity = report.SeverityError
+		if reportErr := r.Report(bad); reportErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to report document: %w", reportErr))
+		}
+		return err
+	}
+
+	// unmapped keys are reported and handled before the document is
+	// evaluated, a rejected listing is still evaluated so every failure
+	// is reported
+	errs := []error{}
+	rejected := false
+	unmapped := map[string]bool{}
+	for _, k := range sortedKeys(doc) {
+		if _, _, ok := s.Schemas(k); ok || s.AdditionalProperties != nil || s.EvalName(k) != nil {
+			continue
+		}
+		unmapped[k] = true
+		observe(k, report.ResultUnmapped)
+		if err := reportUnmapped(s, r, bad, k, doc[k]); err != nil {
+			errs = append(errs, err)
+		}
+		switch s.Unmapped {
+		case schema.UnmappedDrop:
+			delete(doc, k)
+		case schema.UnmappedQuarantine:
+			l.Extras = append(l.Extras, KeyVal{Key: k, Value: doc[k]})
+			delete(doc, k)
+		case schema.UnmappedReject:
+			rejected = true
+		}
+	}
+
+	out, err := s.EvalWith(doc, schema.EvalOptions{
+		Coerce:  coerce,
+		Partial: true,
+		Audit: func(e schema.AuditEntry) {
+			l.Audit = append(l.Audit, e)
+		},
+	})
+	valid, ok := out.(map[string]any)
+	if ok {
+		l.Data = FromDocument(valid)
+	}
+
+	// report every failing key on its own when the failures are known by
+	// key, each with the severity of its own failure
+	failures := map[string]error{}
+	var pe *schema.PropertiesError
+	if errors.As(err, &pe) {
+		for k, e := range pe.Keys {
+			failures[k] = e
+		}
+		// a missing required key is not tied to any key sent
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				if errors.Is(e, schema.ErrRequired) {
+					failures[""] = e
+				}
+			}
+		}
+	} else if err != nil {
+		failures[""] = err
+	}
+	for _, k := range sortedKeys(failures) {
+		e := bad
+		e.Severity = report.SeverityError
+		e.Error = failures[k]
+		if schema.IsWarning(e.Error) {
+			e.Severity = report.SeverityWarning
+			if errors.Is(e.Error, schema.ErrDeprecated) {
+				e.Event = report.EventDeprecated
+			}
+		}
+		if k != "" {
+			e.Key, _, _ = s.Lookup(k)
+			if e.Key != k {
+				e.SourceKey = k
+			}
+			e.Value = doc[k]
+		}
+		if reportErr := r.Report(e); reportErr != nil {
+			errs = append(errs, fmt.Errorf("failed to report bad key <%s>: %w", k, reportErr))
+		}
+	}
+
+	// the other keys were accepted, possibly coerced
+	accepted := []KeyVal{}
+	for _, k := range sortedKeys(doc) {
+		if unmapped[k] {
+			continue
+		}
+		name, _, _ := s.Schemas(k)
+		if f, failed := failures[k]; failed && !schema.IsWarning(f) {
+			observe(name, report.ResultRejected)
+			continue
+		}
+		accepted = append(accepted, KeyVal{Key: k, Value: doc[k]})
+		res := report.ResultAccepted
+		if !reflect.DeepEqual(valid[name], doc[k]) {
+			res = report.ResultCoerced
+		}
+		observe(name, res)
+	}
+
+	if err != nil && !schema.IsWarning(err) {
+		errs = append([]error{err}, errs...)
+	}
+	if rejected {
+		return l.reject(s, r, accepted, errs)
+	}
+	return errors.Join(errs...)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,264 @@
+package approach_3
+
+import (
+	"cmenke/go-playground/lib/approach_3/report"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToDocument(t *testing.T) {
+	l := Listing{Data: []KeyVal{
+		{Key: "ListPrice", Value: 1.0},
+		{Key: "Remarks", Value: "nice"},
+		{Key: "ListPrice", Value: 2.0},
+		{Key: "ListPrice", Value: []any{3.0}},
+	}}
+
+	testCases := []struct {
+		description string
+		policy      DuplicatePolicy
+		expected    map[string]any
+		expectedErr error
+	}{
+		{
+			description: "it should reject duplicate keys by default",
+			expectedErr: schema.ErrDuplicateKey,
+		},
+		{
+			description: "it should keep the first value",
+			policy:      DuplicateFirst,
+			expected:    map[string]any{"ListPrice": 1.0, "Remarks": "nice"},
+		},
+		{
+			description: "it should keep the last value",
+			policy:      DuplicateLast,
+			expected:    map[string]any{"ListPrice": []any{3.0}, "Remarks": "nice"},
+		},
+		{
+			description: "it should collect every value in order",
+			policy:      DuplicateCollect,
+			expected:    map[string]any{"ListPrice": []any{1.0, 2.0, []any{3.0}}, "Remarks": "nice"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		doc, err := l.ToDocument(testCase.policy)
+		if testCase.expectedErr != nil {
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("%s: expected error <%s>, got <%v>", testCase.description, testCase.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error <%s>", testCase.description, err)
+		}
+		if !reflect.DeepEqual(doc, testCase.expected) {
+			t.Fatalf("%s: document <%v> does not match expected <%v>", testCase.description, doc, testCase.expected)
+		}
+	}
+}
+
+func TestDocumentRoundTrip(t *testing.T) {
+	data := []KeyVal{
+		{Key: "Appliances", Value: []any{"oven"}},
+		{Key: "Geo", Value: map[string]any{"Lat": 1.0}},
+		{Key: "ListPrice", Value: 1.0},
+		{Key: "Remarks", Value: nil},
+	}
+	l := Listing{Data: data}
+	doc, err := l.ToDocument(DuplicateReject)
+	if err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	if back := FromDocument(doc); !reflect.DeepEqual(back, data) {
+		t.Fatalf("round trip <%v> does not match <%v>", back, data)
+	}
+}
+
+func TestValidateDocument(t *testing.T) {
+	s, err := schema.Load(strings.NewReader(`{
+		"type": "object",
+		"required": ["ListPrice"],
+		"properties": {
+			"ListPrice": { "type": "number" },
+			"Remarks": { "type": "string" }
+		},
+		"propertyNames": { "enum": ["ListPrice", "Remarks"] }
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+
+	testCases := []struct {
+		description  string
+		data         []KeyVal
+		expectedData []KeyVal
+		expectedKeys []string
+	}{
+		{
+			description:  "it should coerce and keep a valid document",
+			data:         []KeyVal{{Key: "Remarks", Value: "nice"}, {Key: "ListPrice", Value: "100"}},
+			expectedData: []KeyVal{{Key: "ListPrice", Value: 100}, {Key: "Remarks", Value: "nice"}},
+			expectedKeys: []string{},
+		},
+		{
+			description:  "it should report and remove every failing key",
+			data:         []KeyVal{{Key: "ListPrice", Value: true}, {Key: "Remarks", Value: "nice"}, {Key: "DontMapMe", Value: 1.0}},
+			expectedData: []KeyVal{{Key: "Remarks", Value: "nice"}},
+			expectedKeys: []string{"DontMapMe", "ListPrice"},
+		},
+		{
+			description:  "it should report a missing required key without a key",
+			data:         []KeyVal{{Key: "Remarks", Value: "nice"}},
+			expectedData: []KeyVal{{Key: "Remarks", Value: "nice"}},
+			expectedKeys: []string{""},
+		},
+		{
+			description:  "it should report and remove failing keys along a missing required key",
+			data:         []KeyVal{{Key: "Remarks", Value: 1.0}},
+			expectedData: []KeyVal{},
+			expectedKeys: []string{"", "Remarks"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		l := Listing{Data: testCase.data}
+		keys := []string{}
+		reporter := ReporterFunc(func(e report.BadKeyVal) error {
+			keys = append(keys, e.Key)
+			return nil
+		})
+		err := l.ValidateDocument(s, reporter, true, DuplicateReject)
+		if (err != nil) != (len(testCase.expectedKeys) > 0) {
+			t.Fatalf("%s: unexpected error <%v>", testCase.description, err)
+		}
+		if !reflect.DeepEqual(l.Data, testCase.expectedData) {
+			t.Fatalf("%s: data <%v> does not match expected <%v>", testCase.description, l.Data, testCase.expectedData)
+		}
+		if !reflect.DeepEqual(keys, testCase.expectedKeys) {
+			t.Fatalf("%s: reported keys <%v> do not match expected <%v>", testCase.description, keys, testCase.expectedKeys)
+		}
+	}
+}
+
+func TestValidateDocumentDeprecated(t *testing.T) {
+	s, err := schema.Load(strings.NewReader(`{
+		"properties": {
+			"ListPrice": { "type": "number" },
+			"LotSizeAcres": { "type": "number", "deprecated": true }
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	l := Listing{Data: []KeyVal{{Key: "ListPrice", Value: 1.0}, {Key: "LotSizeAcres", Value: 2.0}}}
+	bad := []report.BadKeyVal{}
+	reporter := ReporterFunc(func(e report.BadKeyVal) error {
+		bad = append(bad, e)
+		return nil
+	})
+	if err := l.ValidateDocument(s, reporter, false, DuplicateReject); err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	if len(l.Data) != 2 {
+		t.Fatalf("expected every key to be kept, got <%v>", l.Data)
+	}
+	if len(bad) != 1 || bad[0].Key != "LotSizeAcres" || bad[0].Event != report.EventDeprecated || bad[0].Severity != report.SeverityWarning {
+		t.Fatalf("expected a single deprecated warning for <LotSizeAcres>, got <%+v>", bad)
+	}
+}
+
+func TestValidateDocumentSeverityByKey(t *testing.T) {
+	s, err := schema.Load(strings.NewReader(`{
+		"properties": {
+			"ListPrice": { "type": "number", "maximum": 100, "x-severity": "warning" },
+			"Bedrooms": { "type": "integer" }
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	l := Listing{Data: []KeyVal{{Key: "ListPrice", Value: 1000.0}, {Key: "Bedrooms", Value: "x"}}}
+	severities := map[string]report.Severity{}
+	reporter := ReporterFunc(func(e report.BadKeyVal) error {
+		severities[e.Key] = e.Severity
+		return nil
+	})
+	if err := l.ValidateDocument(s, reporter, false, DuplicateReject); err == nil {
+		t.Fatalf("expected an error for <Bedrooms>")
+	}
+	expected := map[string]report.Severity{"ListPrice": report.SeverityWarning, "Bedrooms": report.SeverityError}
+	if !reflect.DeepEqual(severities, expected) {
+		t.Fatalf("severities <%v> do not match expected <%v>", severities, expected)
+	}
+}
+
+func TestValidateDocumentUnmapped(t *testing.T) {
+	testCases := []struct {
+		description      string
+		policy           string
+		expectedData     []KeyVal
+		expectedExtras   []KeyVal
+		expectedRejected bool
+		expectedResults  []report.Result
+	}{
+		{
+			description:     "it should accept unmapped keys by default",
+			expectedData:    []KeyVal{{Key: "DontMapMe", Value: "555-555-5555"}, {Key: "ListPrice", Value: 1}},
+			expectedResults: []report.Result{report.ResultUnmapped, report.ResultCoerced},
+		},
+		{
+			description:     "it should drop unmapped keys",
+			policy:          schema.UnmappedDrop,
+			expectedData:    []KeyVal{{Key: "ListPrice", Value: 1}},
+			expectedResults: []report.Result{report.ResultUnmapped, report.ResultCoerced},
+		},
+		{
+			description:     "it should quarantine unmapped keys to extras",
+			policy:          schema.UnmappedQuarantine,
+			expectedData:    []KeyVal{{Key: "ListPrice", Value: 1}},
+			expectedExtras:  []KeyVal{{Key: "DontMapMe", Value: "555-555-5555"}},
+			expectedResults: []report.Result{report.ResultUnmapped, report.ResultCoerced},
+		},
+		{
+			description:      "it should reject the whole listing with unmapped keys",
+			policy:           schema.UnmappedReject,
+			expectedRejected: true,
+			expectedResults:  []report.Result{report.ResultUnmapped, report.ResultCoerced},
+		},
+	}
+
+	for _, testCase := range testCases {
+		s := &schema.Schema{Properties: &map[string]*schema.Schema{"ListPrice": {Type: schema.Type{"number"}}}, Unmapped: testCase.policy}
+		l := Listing{Data: []KeyVal{{Key: "ListPrice", Value: "1"}, {Key: "DontMapMe", Value: "555-555-5555"}}}
+		reporter := &countingReporter{}
+		err := l.ValidateDocument(s, reporter, true, DuplicateReject)
+
+		if l.Rejected != testCase.expectedRejected || errors.Is(err, ErrListingRejected) != testCase.expectedRejected {
+			t.Fatalf("%s: expected rejected <%t>, got <%t> with error <%v>", testCase.description, testCase.expectedRejected, l.Rejected, err)
+		}
+		if !reflect.DeepEqual(l.Data, testCase.expectedData) {
+			t.Fatalf("%s: data <%v> does not match expected <%v>", testCase.description, l.Data, testCase.expectedData)
+		}
+		if !reflect.DeepEqual(l.Extras, testCase.expectedExtras) {
+			t.Fatalf("%s: extras <%v> do not match expected <%v>", testCase.description, l.Extras, testCase.expectedExtras)
+		}
+		results := []report.Result{}
+		for _, o := range reporter.outcomes {
+			results = append(results, o.Result)
+		}
+		if !reflect.DeepEqual(results, testCase.expectedResults) {
+			t.Fatalf("%s: observed results <%v> do not match expected <%v>", testCase.description, results, testCase.expectedResults)
+		}
+		if reporter.reports[0].Key != "DontMapMe" || reporter.reports[0].Event != report.EventUnmapped {
+			t.Fatalf("%s: expected DontMapMe to be reported as unmapped, got <%+v>", testCase.description, reporter.reports)
+		}
+		// the valid ListPrice of a rejected listing is reported too
+		if testCase.expectedRejected && (len(reporter.reports) != 2 || reporter.reports[1].Key != "ListPrice") {
+			t.Fatalf("%s: expected ListPrice to be reported, got <%+v>", testCase.description, reporter.reports)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package infer
+
+import (
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Options tune which constraints the Inferrer proposes
+type Options struct {
+	// MaxEnum is the highest number of distinct strings a key can have to
+	// get an enum proposed. 0 disables enums
+	MaxEnum int
+	// MinEnumSamples is the lowest number of string values a key must
+	// have been seen with before an enum is proposed
+	MinEnumSamples int
+}
+
+var DefaultOptions = Options{
+	MaxEnum:        10,
+	MinEnumSamples: 20,
+}
+
+// Constraint is a single inferred schema keyword along with the statistics
+// backing it
+type Constraint struct {
+	Path       string  `json:"path"` // json pointer into the proposed schema
+	Keyword    string  `json:"keyword"`
+	Value      any     `json:"value"`
+	Support    int     `json:"support"` // observations agreeing with the constraint
+	Total      int     `json:"total"`   // observations seen at path
+	Confidence float64 `json:"confidence"`
+	Note       string  `json:"note,omitempty"`
+}
+
+// node accumulates everything observed at one location of the documents
+type node struct {
+	count       int
+	types       map[string]int
+	stringified map[string]int
+	strings     map[string]int // distinct strings, nil once over MaxEnum
+	numStrings  int            // strings holding a json number
+	intStrings  int            // strings holding a whole json number
+	strMin      float64        // smallest number held by a string
+	strMax      float64        // largest number held by a string
+	boolStrings int            // strings holding a json boolean
+	numbers     int
+	integers    int // numbers that are whole
+	min, max    float64
+	items       *node
+	props       map[string]*node
+	objects     int            // number of objects seen, to tell required-ness
+	presence    map[string]int // number of objects each property was seen in
+}
+
+func newNode() *node {
+	return &node{
+		types:       map[string]int{},
+		stringified: map[string]int{},
+		strings:     map[string]int{},
+		props:       map[string]*node{},
+		presence:    map[string]int{},
+	}
+}
+
+// Inferrer proposes a schema.Schema from a corpus of listings. every key of
+// a listing is a property of the proposed root schema
+type Inferrer struct {
+	opts Options
+	root *node
+}
+
+func New(opts Options) *Inferrer {
+	return &Inferrer{opts: opts, root: newNode()}
+}
+
+// Add observes every key:value of l
+func (in *Inferrer) Add(l approach_3.Listing) {
+	in.root.objects++
+	seen := map[string]bool{}
+	for _, kv := range l.Data {
+		if !seen[kv.Key] {
+			seen[kv.Key] = true
+			in.root.presence[kv.Key]++
+		}
+		in.root.prop(kv.Key).observe(kv.Value, in.opts)
+	}
+}
+
+func (n *node) prop(k string) *node {
+	p, ok := n.props[k]
+	if !ok {
+		p = newNode()
+		n.props[k] = p
+	}
+	return p
+}
+
+func (n *node) observe(v any, opts Options) {
+	n.count++
+
+	// strings holding json arrays or objects are inferred as the type
+	// they hold since coercion will turn them into it
+	if str, ok := v.(string); ok {
+		if trimmed := strings.TrimSpace(str); strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+			var parsed any
+			if err := json.Unmarshal([]byte(trimmed), &parsed); err == nil {
+				n.stringified[schema.GetDataType(parsed)]++
+				v = parsed
+			}
+		}
+	}
+
+	t := schema.GetDataType(v)
+	if t == "integer" {
+		// integers are counted as numbers, the build proposes the
+		// integer type once every number seen is whole
+		t = "number"
+	}
+	n.types[t]++
+
+	switch val := v.(type) {
+	case string:
+		// strconv also parses NaN and infinities, which are no json number
+		if f, err := strconv.ParseFloat(val, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+			if n.numStrings == 0 || f < n.strMin {
+				n.strMin = f
+			}
+			if n.numStrings == 0 || f > n.strMax {
+				n.strMax = f
+			}
+			n.numStrings++
+			if f == math.Trunc(f) {
+				n.intStrings++
+			}
+		} else if val == "true" || val == "false" {
+			n.boolStrings++
+		}
+		if n.strings != nil {
+			n.strings[val]++
+			if len(n.strings) > opts.MaxEnum {
+				n.strings = nil
+			}
+		}
+	case []any:
+		if n.items == nil {
+			n.items = newNode()
+		}
+		for _, item := range val {
+			n.items.observe(item, opts)
+		}
+	case map[string]any:
+		n.objects++
+		for k, pv := range val {
+			n.presence[k]++
+			n.prop(k).observe(pv, opts)
+		}
+	default:
+		if f, ok := toFloat(v); ok {
+			if n.numbers == 0 || f < n.min {
+				n.min = f
+			}
+			if n.numbers == 0 || f > n.max {
+				n.max = f
+			}
+			n.numbers++
+			if f == math.Trunc(f) && !math.IsInf(f, 0) {
+				n.integers++
+			}
+		}
+	}
+}
+
+// Schema returns the proposed schema along with the constraints it is
+// made of and their confidence
+func (in *Inferrer) Schema() (*schema.Schema, []Constraint) {
+	constraints := []Constraint{}
+	s := &schema.Schema{}
+	in.root.buildObject(s, "", in.opts, &constraints)
+	return s, constraints
+}
+
+func (n *node) build(path string, opts Options, constraints *[]Constraint) *schema.Schema {
+	s := &schema.Schema{}
+
+	// work on copies so the node can keep observing after a build
+	types := map[string]int{}
+	for t, c := range n.types {
+		types[t] = c
+	}
+	stringified := map[string]int{}
+	for t, c := range n.stringified {
+		stringified[t] = c
+	}
+	enumStrings := n.strings
+
+	// a key seen with numbers, or booleans, and strings that all hold
+	// one of them is that type with some values stringified
+	integral := n.integers == n.numbers
+	min, max, numbers := n.min, n.max, n.numbers
+	for _, t := range []struct {
+		name    string
+		strings int
+	}{{"number", n.numStrings}, {"boolean", n.boolStrings}} {
+		if types[t.name] > 0 && types["string"] > 0 && types["string"] == t.strings {
+			types[t.name] += t.strings
+			stringified[t.name] += t.strings
+			delete(types, "string")
+			enumStrings = nil
+			if t.name == "number" {
+				integral = integral && n.intStrings == n.numStrings
+				// the stringified numbers are bounded like the others
+				min, max = math.Min(min, n.strMin), math.Max(max, n.strMax)
+				numbers += n.numStrings
+			}
+		}
+	}
+	// numbers that are all whole are proposed as integers
+	if types["number"] > 0 && integral {
+		types["integer"], stringified["integer"] = types["number"], stringified["number"]
+		delete(types, "number")
+		delete(stringified, "number")
+	}
+
+	typeNames := make([]string, 0, len(types))
+	for t := range types {
+		typeNames = append(typeNames, t)
+	}
+	sort.Strings(typeNames)
+	s.Type = schema.Type(typeNames)
+	*constraints = append(*constraints, Constraint{
+		Path:       path + "/type",
+		Keyword:    "type",
+		Value:      typeNames,
+		Support:    n.count,
+		Total:      n.count,
+		Confidence: wilsonLower(n.count, n.count),
+		Note:       typeNote(typeNames, types, stringified),
+	})
+
+	assertions := false
+	if len(typeNames) == 1 && typeNames[0] == "string" && enumStrings != nil && opts.MaxEnum > 0 && types["string"] >= opts.MinEnumSamples {
+		enum := make([]string, 0, len(enumStrings))
+		singletons := 0
+		for str, c := range enumStrings {
+			enum = append(enum, str)
+			if c == 1 {
+				singletons++
+			}
+		}
+		sort.Strings(enum)
+		for _, str := range enum {
+			s.Enum = append(s.Enum, str)
+		}
+		assertions = true
+		// good-turing estimate of the chance the next value is one not
+		// seen yet, so a handful of repeated values is a confident enum
+		*constraints = append(*constraints, Constraint{
+			Path:       path + "/enum",
+			Keyword:    "enum",
+			Value:      enum,
+			Support:    n.types["string"],
+			Total:      n.types["string"],
+			Confidence: 1 - float64(singletons)/float64(n.types["string"]),
+			Note:       fmt.Sprintf("%d distinct values, %d seen once", len(enum), singletons),
+		})
+	}
+
+	if numbers > 0 {
+		s.Minimum = &min
+		s.Maximum = &max
+		assertions = true
+		for _, c := range []struct {
+			keyword string
+			value   float64
+		}{{"minimum", min}, {"maximum", max}} {
+			*constraints = append(*constraints, Constraint{
+				Path:       path + "/" + c.keyword,
+				Keyword:    c.keyword,
+				Value:      c.value,
+				Support:    numbers,
+				Total:      numbers,
+				Confidence: wilsonLower(numbers, numbers),
+			})
+		}
+	}
+
+	// inferred assertions only reflect the sample, so they are proposed
+	// as warnings to never drop data until reviewed
+	if assertions {
+		s.Severity = schema.SeverityWarning
+	}
+
+	if n.items != nil && n.items.count > 0 {
+		s.Items = n.items.build(path+"/items", opts, constraints)
+	}
+
+	if len(n.props) > 0 {
+		n.buildObject(s, path, opts, constraints)
+	}
+	return s
+}
+
+func (n *node) buildObject(s *schema.Schema, path string, opts Options, constraints *[]Constraint) {
+	props := map[string]*schema.Schema{}
+	keys := make([]string, 0, len(n.props))
+	for k := range n.props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		props[k] = n.props[k].build(path+"/properties/"+escapePointer(k), opts, constraints)
+
+		present := n.presence[k]
+		if present == n.objects {
+			s.Required = append(s.Required, k)
+		}
+		*constraints = append(*constraints, Constraint{
+			Path:       path + "/required",
+			Keyword:    "required",
+			Value:      k,
+			Support:    present,
+			Total:      n.objects,
+			Confidence: wilsonLower(present, n.objects),
+			Note:       requiredNote(present, n.objects),
+		})
+	}
+	s.Properties = &props
+}
+
+func typeNote(typeNames []string, types, stringified map[string]int) string {
+	parts := []string{}
+	for _, t := range typeNames {
+		part := fmt.Sprintf("%s: %d", t, types[t])
+		if c := stringified[t]; c > 0 {
+			part += fmt.Sprintf(" (%d stringified)", c)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func requiredNote(present, total int) string {
+	if present == total {
+		return "present in every object, proposed as required"
+	}
+	return fmt.Sprintf("present in %d of %d objects, proposed as optional", present, total)
+}
+
+// wilsonLower is the lower bound of the 95% wilson score interval of
+// support out of total, a share that is only trusted with enough samples
+func wilsonLower(support, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	const z = 1.96
+	n := float64(total)
+	p := float64(support) / n
+	denom := 1 + z*z/n
+	center := p + z*z/(2*n)
+	margin := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n))
+	return (center - margin) / denom
+}
+
+func escapePointer(k string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(k, "~", "~0"), "/", "~1")
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
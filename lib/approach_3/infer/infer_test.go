@@ -0,0 +1,94 @@
+package infer
+
+import (
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/report"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestInferSchema(t *testing.T) {
+	corpus := []approach_3.Listing{}
+	for i := 0; i < 30; i++ {
+		data := []approach_3.KeyVal{
+			{Key: "ListPrice", Value: float64(100000 + i)},
+			{Key: "LotSizeAcres", Value: float64(i) + 0.5},
+			{Key: "Stories", Value: []any{float64(2), "2", "NaN", "Infinity"}[i%4]},
+			{Key: "Status", Value: []any{"Active", "Pending", "Closed"}[i%3]},
+			{Key: "Appliances", Value: `["oven", "fridge"]`},
+			{Key: "Remarks", Value: fmt.Sprintf("remark %d", i)},
+			{Key: "Geo", Value: []any{map[string]any{"identifier": "GeoNSRF", "Timezone": map[string]any{"Name": "America/Los_Angeles"}}}},
+		}
+		if i%2 == 0 {
+			data = append(data, approach_3.KeyVal{Key: "ListPrice", Value: "99000"})
+		}
+		if i%5 == 0 {
+			data = append(data, approach_3.KeyVal{Key: "Pool", Value: true})
+		}
+		corpus = append(corpus, approach_3.Listing{DocId: fmt.Sprint(i), Mls: "test", Data: data})
+	}
+
+	in := New(DefaultOptions)
+	for _, l := range corpus {
+		in.Add(l)
+	}
+	s, constraints := in.Schema()
+	props := *s.Properties
+
+	expectedTypes := map[string]schema.Type{
+		"ListPrice":    {"integer"},
+		"LotSizeAcres": {"number"},
+		"Stories":      {"integer", "string"},
+		"Status":       {"string"},
+		"Appliances":   {"array"},
+		"Remarks":      {"string"},
+		"Geo":          {"array"},
+		"Pool":         {"boolean"},
+	}
+	for k, expected := range expectedTypes {
+		if !reflect.DeepEqual(props[k].Type, expected) {
+			t.Fatalf("expected key <%s> to be inferred as <%v>, got <%v>", k, expected, props[k].Type)
+		}
+	}
+
+	if !reflect.DeepEqual(props["Status"].Enum, []any{"Active", "Closed", "Pending"}) {
+		t.Fatalf("expected Status enum to be proposed, got <%v>", props["Status"].Enum)
+	}
+	if props["Remarks"].Enum != nil {
+		t.Fatalf("expected no enum for high cardinality Remarks, got <%v>", props["Remarks"].Enum)
+	}
+	// stringified numbers are folded into the range like the others
+	if *props["ListPrice"].Minimum != 99000 || *props["ListPrice"].Maximum != 100029 {
+		t.Fatalf("unexpected ListPrice range <%v, %v>", *props["ListPrice"].Minimum, *props["ListPrice"].Maximum)
+	}
+	if !reflect.DeepEqual(props["Appliances"].Items.Type, schema.Type{"string"}) {
+		t.Fatalf("expected stringified Appliances items to be strings, got <%v>", props["Appliances"].Items.Type)
+	}
+	geoItem := props["Geo"].Items
+	if !reflect.DeepEqual(geoItem.Required, []string{"Timezone", "identifier"}) {
+		t.Fatalf("unexpected Geo item required <%v>", geoItem.Required)
+	}
+	if !reflect.DeepEqual(s.Required, []string{"Appliances", "Geo", "ListPrice", "LotSizeAcres", "Remarks", "Status", "Stories"}) {
+		t.Fatalf("unexpected root required <%v>", s.Required)
+	}
+
+	for _, c := range constraints {
+		if c.Path == "/required" && c.Value == "Pool" && c.Support != 6 {
+			t.Fatalf("expected Pool to be present in 6 listings, got <%+v>", c)
+		}
+		if c.Confidence < 0 || c.Confidence > 1 {
+			t.Fatalf("confidence out of range for <%+v>", c)
+		}
+	}
+
+	// the proposed schema must accept the corpus it was inferred from
+	failing := approach_3.ReporterFunc(func(e report.BadKeyVal) error {
+		t.Fatalf("inferred schema rejected key <%s> with value <%v>: %s", e.Key, e.Value, e.Error)
+		return nil
+	})
+	for _, l := range corpus {
+		_ = l.Validate(s, failing, true)
+	}
+}
@@ -0,0 +1,6 @@
+// Package listing holds the Go types generated from the repository
+// schema.json, validating and coercing values the way schema.Eval does
+// without interpreting the schema at runtime.
+package listing
+
+//go:generate go run ../../.. gen --schema ../../../schema.json --package listing --out listing_gen.go
@@ -0,0 +1,830 @@
+// Code generated by jsonschema gen from schema.json. DO NOT EDIT.
+
+package listing
+
+import (
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Coerce tells UnmarshalJSON whether to coerce stringified values into
+// their schema type
+var Coerce = true
+
+// coerceString tries to parse v as each of types in order, returning the
+// first that works
+func coerceString(v string, types ...string) (any, error) {
+	var err error
+	for _, t := range types {
+		switch t {
+		case "null":
+			return nil, nil
+		case "boolean":
+			if v == "true" {
+				return true, nil
+			} else if v == "false" {
+				return false, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to boolean", v), err)
+		case "integer":
+			intVal, intErr := strconv.Atoi(v)
+			if intErr == nil {
+				return intVal, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to integer: %s", v, intErr), err)
+		case "number":
+			intVal, intErr := strconv.Atoi(v)
+			if intErr == nil {
+				return intVal, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to integer: %s", v, intErr), err)
+			floatVal, floatErr := strconv.ParseFloat(v, 64)
+			if floatErr == nil {
+				return floatVal, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to float: %s", v, floatErr), err)
+		case "array":
+			var arr []any
+			arrErr := json.Unmarshal([]byte(v), &arr)
+			if arrErr == nil {
+				return arr, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to array: %s", v, arrErr), err)
+		case "object":
+			var obj map[string]any
+			objErr := json.Unmarshal([]byte(v), &obj)
+			if objErr == nil {
+				return obj, nil
+			}
+			err = errors.Join(fmt.Errorf("failed to parse value <%s> to obj: %s", v, objErr), err)
+		}
+	}
+	return nil, err
+}
+
+// toFloat returns v as a float64 if it is a number
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func toFloat64(v any) float64 {
+	n, _ := toFloat(v)
+	return n
+}
+
+func toInt(v any) int {
+	if n, ok := v.(int); ok {
+		return n
+	}
+	return int(toFloat64(v))
+}
+
+// inEnum reports whether v is one of enum, comparing numbers by value
+func inEnum(enum []any, v any) bool {
+	n, isNum := toFloat(v)
+	for _, e := range enum {
+		if en, ok := toFloat(e); ok && isNum {
+			if en == n {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// evalListing evaluates a value against </>
+func evalListing(v any, coerce bool) (any, error) {
+	warns := []error{}
+	{
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("value <%v> cannot be evaluated as an object", v)
+		}
+		valid := map[string]any{}
+		errs := []error{}
+		propWarns := []error{}
+		for k, pv := range obj {
+			var eval func(any, bool) (any, error)
+			switch k {
+			case "Appliances":
+				eval = evalAppliances
+			case "Geo":
+				eval = evalGeo
+			case "ListPrice":
+				eval = evalListPrice
+			case "NumArray":
+				eval = evalNumArray
+			}
+			if eval == nil {
+				valid[k] = pv
+				continue
+			}
+			ev, err := eval(pv, coerce)
+			if schema.IsWarning(err) {
+				propWarns = append(propWarns, fmt.Errorf("key <%s>: %w", k, err))
+			} else if err != nil {
+				errs = append(errs, fmt.Errorf("key <%s>: %w", k, err))
+				continue
+			}
+			valid[k] = ev
+		}
+		if len(errs) > 0 {
+			return nil, errors.Join(fmt.Errorf("%w: %v", schema.ErrInvalidObject, obj), fmt.Errorf("\tcould not validate all key:vals in obj: %v", errs))
+		}
+		if len(propWarns) > 0 {
+			warns = append(warns, &schema.Warning{Err: errors.Join(propWarns...)})
+		}
+		v = valid
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// Listing is the Go type of </>
+type Listing struct {
+	Appliances []string  `json:"Appliances,omitempty"`
+	Geo        []GeoItem `json:"Geo,omitempty"`
+	ListPrice  *float64  `json:"ListPrice,omitempty"`
+	NumArray   []float64 `json:"NumArray,omitempty"`
+}
+
+// UnmarshalJSON validates the value, coercing it when Coerce is set,
+// before decoding it
+func (x *Listing) UnmarshalJSON(b []byte) error {
+	var raw any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	v, err := evalListing(raw, Coerce)
+	if err != nil && !schema.IsWarning(err) {
+		return err
+	}
+	*x = convListing(v)
+	return nil
+}
+
+func convListing(v any) Listing {
+	obj := v.(map[string]any)
+	var x Listing
+	if fv, ok := obj["Appliances"]; ok {
+		x.Appliances = convAppliances(fv)
+	}
+	if fv, ok := obj["Geo"]; ok {
+		x.Geo = convGeo(fv)
+	}
+	if fv, ok := obj["ListPrice"]; ok {
+		x.ListPrice = ptr(toFloat64(fv))
+	}
+	if fv, ok := obj["NumArray"]; ok {
+		x.NumArray = convNumArray(fv)
+	}
+	return x
+}
+
+// evalAppliances evaluates a value against </properties/Appliances>
+func evalAppliances(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "array")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"array"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "array")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	{
+		items, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("value <%v> with type <%T> cannot be evaluated as an array", v, v)
+		}
+		valid := []any{}
+		errs := []error{}
+		itemWarns := []error{}
+		for i, item := range items {
+			iv, err := evalAppliancesItem(item, coerce)
+			if schema.IsWarning(err) {
+				itemWarns = append(itemWarns, fmt.Errorf("item %d: %w", i, err))
+			} else if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			valid = append(valid, iv)
+		}
+		if len(errs) > 0 {
+			return nil, errors.Join(fmt.Errorf("%w: %v", schema.ErrInvalidItems, items), fmt.Errorf("\terror: could not validate all items in array: %v", errs))
+		}
+		if len(itemWarns) > 0 {
+			warns = append(warns, &schema.Warning{Err: errors.Join(itemWarns...)})
+		}
+		v = valid
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+func convAppliances(v any) []string {
+	items := v.([]any)
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = item.(string)
+	}
+	return out
+}
+
+// evalAppliancesItem evaluates a value against </properties/Appliances/items>
+func evalAppliancesItem(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "string")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"string"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "string")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalGeo evaluates a value against </properties/Geo>
+func evalGeo(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "array")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"array"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "array")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	{
+		items, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("value <%v> with type <%T> cannot be evaluated as an array", v, v)
+		}
+		valid := []any{}
+		errs := []error{}
+		itemWarns := []error{}
+		for i, item := range items {
+			iv, err := evalGeoItem(item, coerce)
+			if schema.IsWarning(err) {
+				itemWarns = append(itemWarns, fmt.Errorf("item %d: %w", i, err))
+			} else if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			valid = append(valid, iv)
+		}
+		if len(errs) > 0 {
+			return nil, errors.Join(fmt.Errorf("%w: %v", schema.ErrInvalidItems, items), fmt.Errorf("\terror: could not validate all items in array: %v", errs))
+		}
+		if len(itemWarns) > 0 {
+			warns = append(warns, &schema.Warning{Err: errors.Join(itemWarns...)})
+		}
+		v = valid
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+func convGeo(v any) []GeoItem {
+	items := v.([]any)
+	out := make([]GeoItem, len(items))
+	for i, item := range items {
+		out[i] = convGeoItem(item)
+	}
+	return out
+}
+
+// evalGeoItem evaluates a value against </properties/Geo/items>
+func evalGeoItem(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "object")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"object"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "object")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	{
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("value <%v> cannot be evaluated as an object", v)
+		}
+		valid := map[string]any{}
+		errs := []error{}
+		propWarns := []error{}
+		for k, pv := range obj {
+			var eval func(any, bool) (any, error)
+			switch k {
+			case "Timezone":
+				eval = evalGeoItemTimezone
+			case "identifier":
+				eval = evalGeoItemIdentifier
+			}
+			if eval == nil {
+				valid[k] = pv
+				continue
+			}
+			ev, err := eval(pv, coerce)
+			if schema.IsWarning(err) {
+				propWarns = append(propWarns, fmt.Errorf("key <%s>: %w", k, err))
+			} else if err != nil {
+				errs = append(errs, fmt.Errorf("key <%s>: %w", k, err))
+				continue
+			}
+			valid[k] = ev
+		}
+		if len(errs) > 0 {
+			return nil, errors.Join(fmt.Errorf("%w: %v", schema.ErrInvalidObject, obj), fmt.Errorf("\tcould not validate all key:vals in obj: %v", errs))
+		}
+		if len(propWarns) > 0 {
+			warns = append(warns, &schema.Warning{Err: errors.Join(propWarns...)})
+		}
+		v = valid
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// GeoItem is the Go type of </properties/Geo/items>
+type GeoItem struct {
+	Timezone   *GeoItemTimezone `json:"Timezone,omitempty"`
+	Identifier *string          `json:"identifier,omitempty"`
+}
+
+// UnmarshalJSON validates the value, coercing it when Coerce is set,
+// before decoding it
+func (x *GeoItem) UnmarshalJSON(b []byte) error {
+	var raw any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	v, err := evalGeoItem(raw, Coerce)
+	if err != nil && !schema.IsWarning(err) {
+		return err
+	}
+	*x = convGeoItem(v)
+	return nil
+}
+
+func convGeoItem(v any) GeoItem {
+	obj := v.(map[string]any)
+	var x GeoItem
+	if fv, ok := obj["Timezone"]; ok {
+		x.Timezone = ptr(convGeoItemTimezone(fv))
+	}
+	if fv, ok := obj["identifier"]; ok {
+		x.Identifier = ptr(fv.(string))
+	}
+	return x
+}
+
+// evalGeoItemTimezone evaluates a value against </properties/Geo/items/properties/Timezone>
+func evalGeoItemTimezone(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "object")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"object"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "object")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	{
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("value <%v> cannot be evaluated as an object", v)
+		}
+		valid := map[string]any{}
+		errs := []error{}
+		propWarns := []error{}
+		for k, pv := range obj {
+			var eval func(any, bool) (any, error)
+			switch k {
+			case "Name":
+				eval = evalGeoItemTimezoneName
+			case "ObservesDLS":
+				eval = evalGeoItemTimezoneObservesDLS
+			case "TimezoneCode":
+				eval = evalGeoItemTimezoneTimezoneCode
+			case "TimezoneStdOffset":
+				eval = evalGeoItemTimezoneTimezoneStdOffset
+			}
+			if eval == nil {
+				valid[k] = pv
+				continue
+			}
+			ev, err := eval(pv, coerce)
+			if schema.IsWarning(err) {
+				propWarns = append(propWarns, fmt.Errorf("key <%s>: %w", k, err))
+			} else if err != nil {
+				errs = append(errs, fmt.Errorf("key <%s>: %w", k, err))
+				continue
+			}
+			valid[k] = ev
+		}
+		if len(errs) > 0 {
+			return nil, errors.Join(fmt.Errorf("%w: %v", schema.ErrInvalidObject, obj), fmt.Errorf("\tcould not validate all key:vals in obj: %v", errs))
+		}
+		if len(propWarns) > 0 {
+			warns = append(warns, &schema.Warning{Err: errors.Join(propWarns...)})
+		}
+		v = valid
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// GeoItemTimezone is the Go type of </properties/Geo/items/properties/Timezone>
+type GeoItemTimezone struct {
+	Name              *string `json:"Name,omitempty"`
+	ObservesDLS       *bool   `json:"ObservesDLS,omitempty"`
+	TimezoneCode      *string `json:"TimezoneCode,omitempty"`
+	TimezoneStdOffset *string `json:"TimezoneStdOffset,omitempty"`
+}
+
+// UnmarshalJSON validates the value, coercing it when Coerce is set,
+// before decoding it
+func (x *GeoItemTimezone) UnmarshalJSON(b []byte) error {
+	var raw any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	v, err := evalGeoItemTimezone(raw, Coerce)
+	if err != nil && !schema.IsWarning(err) {
+		return err
+	}
+	*x = convGeoItemTimezone(v)
+	return nil
+}
+
+func convGeoItemTimezone(v any) GeoItemTimezone {
+	obj := v.(map[string]any)
+	var x GeoItemTimezone
+	if fv, ok := obj["Name"]; ok {
+		x.Name = ptr(fv.(string))
+	}
+	if fv, ok := obj["ObservesDLS"]; ok {
+		x.ObservesDLS = ptr(fv.(bool))
+	}
+	if fv, ok := obj["TimezoneCode"]; ok {
+		x.TimezoneCode = ptr(fv.(string))
+	}
+	if fv, ok := obj["TimezoneStdOffset"]; ok {
+		x.TimezoneStdOffset = ptr(fv.(string))
+	}
+	return x
+}
+
+// evalGeoItemTimezoneName evaluates a value against </properties/Geo/items/properties/Timezone/properties/Name>
+func evalGeoItemTimezoneName(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "string")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"string"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "string")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalGeoItemTimezoneObservesDLS evaluates a value against </properties/Geo/items/properties/Timezone/properties/ObservesDLS>
+func evalGeoItemTimezoneObservesDLS(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "boolean")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"boolean"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "boolean")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalGeoItemTimezoneTimezoneCode evaluates a value against </properties/Geo/items/properties/Timezone/properties/TimezoneCode>
+func evalGeoItemTimezoneTimezoneCode(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "string")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"string"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "string")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalGeoItemTimezoneTimezoneStdOffset evaluates a value against </properties/Geo/items/properties/Timezone/properties/TimezoneStdOffset>
+func evalGeoItemTimezoneTimezoneStdOffset(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "string")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"string"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "string")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalGeoItemIdentifier evaluates a value against </properties/Geo/items/properties/identifier>
+func evalGeoItemIdentifier(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "string")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"string"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "string")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalListPrice evaluates a value against </properties/ListPrice>
+func evalListPrice(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "number")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"number"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "number")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	assertErrs := []error{}
+	if n, ok := toFloat(v); ok {
+		if n > float64(5e+07) {
+			assertErrs = append(assertErrs, fmt.Errorf("%w, the value <%v> is greater than the maximum <%v>", schema.ErrRange, v, float64(5e+07)))
+		}
+	}
+	if err := errors.Join(assertErrs...); err != nil {
+		warns = append(warns, err)
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// evalNumArray evaluates a value against </properties/NumArray>
+func evalNumArray(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "array")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"array"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "array")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	{
+		items, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("value <%v> with type <%T> cannot be evaluated as an array", v, v)
+		}
+		valid := []any{}
+		errs := []error{}
+		itemWarns := []error{}
+		for i, item := range items {
+			iv, err := evalNumArrayItem(item, coerce)
+			if schema.IsWarning(err) {
+				itemWarns = append(itemWarns, fmt.Errorf("item %d: %w", i, err))
+			} else if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			valid = append(valid, iv)
+		}
+		if len(errs) > 0 {
+			return nil, errors.Join(fmt.Errorf("%w: %v", schema.ErrInvalidItems, items), fmt.Errorf("\terror: could not validate all items in array: %v", errs))
+		}
+		if len(itemWarns) > 0 {
+			warns = append(warns, &schema.Warning{Err: errors.Join(itemWarns...)})
+		}
+		v = valid
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+func convNumArray(v any) []float64 {
+	items := v.([]any)
+	out := make([]float64, len(items))
+	for i, item := range items {
+		out[i] = toFloat64(item)
+	}
+	return out
+}
+
+// evalNumArrayItem evaluates a value against </properties/NumArray/items>
+func evalNumArrayItem(v any, coerce bool) (any, error) {
+	warns := []error{}
+	if !(schema.MatchesType(v, "number")) {
+		valType := schema.GetDataType(v)
+		err := fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", schema.ErrTypeMismatch, v, valType, []string{"number"})
+		str, ok := v.(string)
+		if !coerce || !ok {
+			return v, err
+		}
+		var coerceErr error
+		v, coerceErr = coerceString(str, "number")
+		if coerceErr != nil {
+			return v, errors.Join(err, schema.ErrCoerce, coerceErr)
+		}
+	}
+	if len(warns) > 0 {
+		return v, &schema.Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// Eval evaluates the value of key against its schema like schema.Eval.
+// known is false for keys without a schema, whose value is accepted as is
+func Eval(key string, v any, coerce bool) (val any, known bool, err error) {
+	switch key {
+	case "Appliances":
+		val, err = evalAppliances(v, coerce)
+		return val, true, err
+	case "Geo":
+		val, err = evalGeo(v, coerce)
+		return val, true, err
+	case "ListPrice":
+		val, err = evalListPrice(v, coerce)
+		return val, true, err
+	case "NumArray":
+		val, err = evalNumArray(v, coerce)
+		return val, true, err
+	}
+	return v, false, nil
+}
+
+// KeyVal is a key:value of listing data. UnmarshalJSON validates the
+// value of known keys and decodes it into its Go type
+type KeyVal struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+func (kv *KeyVal) UnmarshalJSON(b []byte) error {
+	var aux struct {
+		Key   string `json:"key"`
+		Value any    `json:"value"`
+	}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	v, _, err := Eval(aux.Key, aux.Value, Coerce)
+	if err != nil && !schema.IsWarning(err) {
+		return fmt.Errorf("key <%s>: %w", aux.Key, err)
+	}
+	kv.Key = aux.Key
+	switch aux.Key {
+	case "Appliances":
+		kv.Value = convAppliances(v)
+	case "Geo":
+		kv.Value = convGeo(v)
+	case "ListPrice":
+		kv.Value = toFloat64(v)
+	case "NumArray":
+		kv.Value = convNumArray(v)
+	default:
+		kv.Value = v
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package listing
+
+import (
+	"bytes"
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/codegen"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+var sentinels = []error{
+	schema.ErrTypeMismatch,
+	schema.ErrCoerce,
+	schema.ErrInvalidItems,
+	schema.ErrInvalidObject,
+	schema.ErrRange,
+	schema.ErrEnum,
+	schema.ErrRequired,
+	schema.ErrAnyOf,
+}
+
+func loadSchema(t testing.TB) *schema.Schema {
+	t.Helper()
+	f, err := os.Open("../../../schema.json")
+	if err != nil {
+		t.Fatalf("failed to open schema: %s", err)
+	}
+	defer f.Close()
+	s, err := schema.Load(f)
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	return s
+}
+
+// interpret evaluates key:value the way Listing.Validate does
+func interpret(s *schema.Schema, key string, v any, coerce bool) (any, bool, error) {
+	ks, ok := (*s.Properties)[key]
+	if !ok {
+		return v, false, nil
+	}
+	val, err := ks.Eval(v, coerce)
+	return val, true, err
+}
+
+// agree fails unless the generated and interpreted results are the same:
+// the same value, and errors both absent, both warnings or both errors
+// wrapping the same sentinels
+func agree(t testing.TB, description string, key string, v any, coerce bool, s *schema.Schema) {
+	t.Helper()
+	// both paths may modify nested values in place, give each its own copy
+	genVal, genKnown, genErr := Eval(key, clone(t, v), coerce)
+	intVal, intKnown, intErr := interpret(s, key, clone(t, v), coerce)
+
+	if genKnown != intKnown {
+		t.Fatalf("%s: key <%s> known <%v> by generated code, <%v> by the interpreter", description, key, genKnown, intKnown)
+	}
+	if !reflect.DeepEqual(genVal, intVal) {
+		t.Fatalf("%s: key <%s> value <%v> coerce <%v>: generated value <%#v> does not match interpreted <%#v>", description, key, v, coerce, genVal, intVal)
+	}
+	if (genErr == nil) != (intErr == nil) || schema.IsWarning(genErr) != schema.IsWarning(intErr) {
+		t.Fatalf("%s: key <%s> value <%v> coerce <%v>: generated error <%v> does not match interpreted <%v>", description, key, v, coerce, genErr, intErr)
+	}
+	for _, sentinel := range sentinels {
+		if errors.Is(genErr, sentinel) != errors.Is(intErr, sentinel) {
+			t.Fatalf("%s: key <%s> value <%v> coerce <%v>: generated error <%v> and interpreted <%v> disagree on <%s>", description, key, v, coerce, genErr, intErr, sentinel)
+		}
+	}
+}
+
+func clone(t testing.TB, v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal <%v>: %s", v, err)
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("failed to unmarshal <%s>: %s", data, err)
+	}
+	return out
+}
+
+func TestGeneratedMatchesInterpreter(t *testing.T) {
+	s := loadSchema(t)
+
+	testCases := []struct {
+		description string
+		key         string
+		value       any
+	}{
+		{"numbers are accepted", "ListPrice", 100000.0},
+		{"numeric strings are coerced", "ListPrice", "100000"},
+		{"float strings are coerced", "ListPrice", "100000.5"},
+		{"out of range numbers are warned about", "ListPrice", 60000000.0},
+		{"non numeric strings are rejected", "ListPrice", "abc"},
+		{"booleans are rejected", "ListPrice", true},
+		{"null is rejected", "ListPrice", nil},
+		{"string arrays are accepted", "Appliances", []any{"oven", "fridge"}},
+		{"stringified arrays are coerced", "Appliances", `["oven", "fridge"]`},
+		{"arrays with wrong items are rejected", "Appliances", []any{"oven", 10.0}},
+		{"malformed stringified arrays are rejected", "Appliances", `["oven"`},
+		{"number items are coerced", "NumArray", []any{1.0, "2", 3.0}},
+		{"empty arrays are accepted", "NumArray", []any{}},
+		{"nested objects are accepted", "Geo", []any{map[string]any{"identifier": "GeoNSRF", "Timezone": map[string]any{"Name": "America/Los_Angeles", "ObservesDLS": true}}}},
+		{"nested values are coerced", "Geo", []any{map[string]any{"Timezone": map[string]any{"ObservesDLS": "true"}}}},
+		{"nested values are rejected", "Geo", []any{map[string]any{"Timezone": map[string]any{"ObservesDLS": "yes"}}}},
+		{"unknown nested keys are kept", "Geo", []any{map[string]any{"Other": 1.0}}},
+		{"non object items are rejected", "Geo", []any{"GeoNSRF"}},
+		{"unmapped keys are accepted as is", "DontMapMe", "555-555-5555"},
+	}
+
+	for _, testCase := range testCases {
+		for _, coerce := range []bool{true, false} {
+			agree(t, testCase.description, testCase.key, testCase.value, coerce, s)
+		}
+	}
+}
+
+func TestGeneratedMatchesInterpreterOnListings(t *testing.T) {
+	s := loadSchema(t)
+	data, err := os.ReadFile("../../../testdata/listings.ndjson")
+	if err != nil {
+		t.Fatalf("failed to read listings: %s", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var l approach_3.Listing
+		if err := dec.Decode(&l); err != nil {
+			t.Fatalf("failed to decode listing: %s", err)
+		}
+		for _, kv := range l.Data {
+			for _, coerce := range []bool{true, false} {
+				agree(t, "docid "+l.DocId, kv.Key, kv.Value, coerce, s)
+			}
+		}
+	}
+}
+
+func FuzzGeneratedMatchesInterpreter(f *testing.F) {
+	s := loadSchema(f)
+	for _, seed := range []string{`1`, `"1"`, `"1.5"`, `["a", 1]`, `"[1, \"2\"]"`, `[{"Timezone": {"ObservesDLS": "false"}}]`, `null`, `{}`} {
+		f.Add(seed, true)
+	}
+	f.Fuzz(func(t *testing.T, value string, coerce bool) {
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			t.Skip()
+		}
+		for _, key := range []string{"ListPrice", "Appliances", "NumArray", "Geo"} {
+			agree(t, "fuzz", key, v, coerce, s)
+		}
+	})
+}
+
+func TestTypedDecoding(t *testing.T) {
+	var kvs []KeyVal
+	err := json.Unmarshal([]byte(`[
+		{"key": "ListPrice", "value": "100000"},
+		{"key": "Appliances", "value": "[\"oven\"]"},
+		{"key": "Geo", "value": [{"identifier": "GeoNSRF", "Timezone": {"ObservesDLS": "true"}}]},
+		{"key": "DontMapMe", "value": "555"}
+	]`), &kvs)
+	if err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	expected := []KeyVal{
+		{Key: "ListPrice", Value: 100000.0},
+		{Key: "Appliances", Value: []string{"oven"}},
+		{Key: "Geo", Value: []GeoItem{{Identifier: ptr("GeoNSRF"), Timezone: &GeoItemTimezone{ObservesDLS: ptr(true)}}}},
+		{Key: "DontMapMe", Value: "555"},
+	}
+	if !reflect.DeepEqual(kvs, expected) {
+		t.Fatalf("expected <%#v>, got <%#v>", expected, kvs)
+	}
+
+	var kv KeyVal
+	if err := json.Unmarshal([]byte(`{"key": "ListPrice", "value": "abc"}`), &kv); !errors.Is(err, schema.ErrCoerce) {
+		t.Fatalf("expected an invalid value to fail decoding with a coerce error, got <%v>", err)
+	}
+
+	var l Listing
+	if err := json.Unmarshal([]byte(`{"ListPrice": 1, "NumArray": [1, "2"]}`), &l); err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	if *l.ListPrice != 1 || !reflect.DeepEqual(l.NumArray, []float64{1, 2}) {
+		t.Fatalf("unexpected listing <%+v>", l)
+	}
+}
+
+// TestGeneratedIsUpToDate fails when schema.json changed without running
+// go generate
+func TestGeneratedIsUpToDate(t *testing.T) {
+	expected, err := codegen.Generate(loadSchema(t), codegen.Options{Package: "listing", Source: "schema.json"})
+	if err != nil {
+		t.Fatalf("failed to generate: %s", err)
+	}
+	got, err := os.ReadFile("listing_gen.go")
+	if err != nil {
+		t.Fatalf("failed to read generated file: %s", err)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Fatalf("listing_gen.go is out of date with schema.json, run go generate ./lib/approach_3/listing")
+	}
+}
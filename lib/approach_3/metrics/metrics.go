@@ -0,0 +1,189 @@
+package metrics
+
+import (
+	"bufio"
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/report"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the validation
+// latency histogram
+var DefaultBuckets = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1}
+
+type labels struct {
+	mls string
+	key string
+}
+
+type histogram struct {
+	counts []uint64 // one per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// Metrics collects counters and latency histograms of validation outcomes,
+// labeled by mls and key, and serves them in the prometheus text
+// exposition format. it is safe for concurrent use
+type Metrics struct {
+	mu        sync.Mutex
+	buckets   []float64
+	validated map[labels]uint64
+	coerced   map[labels]uint64
+	rejected  map[labels]uint64
+	unmapped  map[labels]uint64
+	latency   map[labels]*histogram
+}
+
+func New() *Metrics {
+	return NewWithBuckets(DefaultBuckets)
+}
+
+// NewWithBuckets returns a Metrics using the given sorted histogram bucket
+// upper bounds, in seconds
+func NewWithBuckets(buckets []float64) *Metrics {
+	return &Metrics{
+		buckets:   buckets,
+		validated: map[labels]uint64{},
+		coerced:   map[labels]uint64{},
+		rejected:  map[labels]uint64{},
+		unmapped:  map[labels]uint64{},
+		latency:   map[labels]*histogram{},
+	}
+}
+
+// Observe records a single validation outcome
+func (m *Metrics) Observe(o report.Outcome) {
+	l := labels{mls: o.Mls, key: o.Key}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch o.Result {
+	case report.ResultUnmapped:
+		m.unmapped[l]++
+		return
+	case report.ResultCoerced:
+		m.coerced[l]++
+	case report.ResultRejected:
+		m.rejected[l]++
+	}
+	m.validated[l]++
+
+	h, ok := m.latency[l]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latency[l] = h
+	}
+	secs := o.Duration.Seconds()
+	for i, upper := range m.buckets {
+		if secs <= upper {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += secs
+	h.count++
+}
+
+// Wrap returns a Reporter forwarding every report to r while recording
+// every outcome observed by approach_3.KeyVal.Validate into m. if r is
+// itself an approach_3.Observer it keeps receiving outcomes
+func (m *Metrics) Wrap(r approach_3.Reporter) approach_3.Reporter {
+	return &reporter{metrics: m, next: r}
+}
+
+type reporter struct {
+	metrics *Metrics
+	next    approach_3.Reporter
+}
+
+func (r *reporter) Report(e report.BadKeyVal) error {
+	return r.next.Report(e)
+}
+
+func (r *reporter) Observe(o report.Outcome) {
+	r.metrics.Observe(o)
+	if next, ok := r.next.(approach_3.Observer); ok {
+		next.Observe(o)
+	}
+}
+
+// ServeHTTP serves the current metrics in the prometheus text format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WriteText(w)
+}
+
+// WriteText writes the current metrics in the prometheus text format
+func (m *Metrics) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	m.mu.Lock()
+	writeCounter(bw, "listing_keys_validated_total", "Number of key:vals evaluated against a schema.", m.validated)
+	writeCounter(bw, "listing_keys_coerced_total", "Number of key:vals accepted after being coerced.", m.coerced)
+	writeCounter(bw, "listing_keys_rejected_total", "Number of key:vals rejected by a schema.", m.rejected)
+	writeCounter(bw, "listing_keys_unmapped_total", "Number of key:vals without a schema mapping.", m.unmapped)
+	m.writeHistogram(bw, "listing_key_validation_duration_seconds", "Latency of validating a single key:val.")
+	m.mu.Unlock()
+
+	return bw.Flush()
+}
+
+func writeCounter(w io.Writer, name, help string, series map[labels]uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s counter\n", name)
+	for _, l := range sortedLabels(series) {
+		fmt.Fprintf(w, "%s{%s} %d\n", name, l.String(), series[l])
+	}
+}
+
+func (m *Metrics) writeHistogram(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", name)
+	for _, l := range sortedLabels(m.latency) {
+		h := m.latency[l]
+		var cumulative uint64
+		for i, upper := range m.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, l.String(), formatFloat(upper), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, l.String(), h.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", name, l.String(), formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, l.String(), h.count)
+	}
+}
+
+func sortedLabels[V any](series map[labels]V) []labels {
+	out := make([]labels, 0, len(series))
+	for l := range series {
+		out = append(out, l)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].mls != out[j].mls {
+			return out[i].mls < out[j].mls
+		}
+		return out[i].key < out[j].key
+	})
+	return out
+}
+
+func (l labels) String() string {
+	return fmt.Sprintf("mls=\"%s\",key=\"%s\"", escapeLabel(l.mls), escapeLabel(l.key))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
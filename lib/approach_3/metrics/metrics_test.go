@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/report"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsWrap(t *testing.T) {
+	var s schema.Schema
+	err := json.Unmarshal([]byte(`{
+		"properties": {
+			"ListPrice": { "type": "number" },
+			"Remarks": { "type": "string" }
+		}
+	}`), &s)
+	if err != nil {
+		t.Fatalf("failed to unmarshal schema: %s", err)
+	}
+
+	l := approach_3.Listing{
+		DocId: "1",
+		Mls:   "rets-properties-test",
+		Data: []approach_3.KeyVal{
+			{Key: "ListPrice", Value: 100000.0},
+			{Key: "ListPrice", Value: "100000"},
+			{Key: "ListPrice", Value: "nope"},
+			{Key: "Remarks", Value: "nice"},
+			{Key: "DontMapMe", Value: "555-555-5555"},
+		},
+	}
+
+	reported := 0
+	m := New()
+	r := m.Wrap(approach_3.ReporterFunc(func(e report.BadKeyVal) error {
+		reported++
+		return nil
+	}))
+	_ = l.Validate(&s, r, true)
+
+	// the rejected ListPrice and the unmapped DontMapMe
+	if reported != 2 {
+		t.Fatalf("expected wrapped reporter to receive 2 reports, got %d", reported)
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	expectedLines := []string{
+		`listing_keys_validated_total{mls="rets-properties-test",key="ListPrice"} 3`,
+		`listing_keys_validated_total{mls="rets-properties-test",key="Remarks"} 1`,
+		`listing_keys_coerced_total{mls="rets-properties-test",key="ListPrice"} 1`,
+		`listing_keys_rejected_total{mls="rets-properties-test",key="ListPrice"} 1`,
+		`listing_keys_unmapped_total{mls="rets-properties-test",key="DontMapMe"} 1`,
+		`listing_key_validation_duration_seconds_bucket{mls="rets-properties-test",key="ListPrice",le="+Inf"} 3`,
+		`listing_key_validation_duration_seconds_count{mls="rets-properties-test",key="Remarks"} 1`,
+		`# TYPE listing_key_validation_duration_seconds histogram`,
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Fatalf("metrics output missing line <%s>:\n%s", line, body)
+		}
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Fatalf("unexpected escaped label <%s>", got)
+	}
+}
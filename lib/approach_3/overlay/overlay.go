@@ -0,0 +1,150 @@
+package overlay
+
+import (
+	"bytes"
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Merge applies overlay to base and returns the compiled effective schema,
+// leaving base untouched.
+//
+// overlay is a json merge patch (RFC 7396) of the base document: objects
+// such as properties are merged key by key, null removes a key and any
+// other value, including arrays like type, enum and required, replaces
+// the base one. so an overlay can add, tighten, loosen or remove
+// properties:
+//
+//	{ "properties": { "ListPrice": { "maximum": null }, "Remarks": null } }
+func Merge(base *schema.Schema, overlay []byte) (*schema.Schema, error) {
+	baseDoc, err := json.Marshal(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal base schema: %w", err)
+	}
+	var doc any
+	if err := json.Unmarshal(baseDoc, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal base schema: %w", err)
+	}
+
+	var patch any
+	if err := json.Unmarshal(overlay, &patch); err != nil {
+		return nil, fmt.Errorf("overlay is not valid json: %w", err)
+	}
+	if _, ok := patch.(map[string]any); !ok {
+		return nil, fmt.Errorf("overlay must be an object, got <%s>", schema.GetDataType(patch))
+	}
+
+	merged, err := json.Marshal(mergePatch(doc, patch))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal merged schema: %w", err)
+	}
+	s, err := schema.Load(bytes.NewReader(merged))
+	if err != nil {
+		return nil, err
+	}
+	// overlays are versioned along with their base, see store
+	s.Version = base.Version
+	return s, nil
+}
+
+// mergePatch applies patch to target as described by RFC 7396
+func mergePatch(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = map[string]any{}
+	}
+
+	out := make(map[string]any, len(targetObj))
+	for k, v := range targetObj {
+		out[k] = v
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(out, k)
+			continue
+		}
+		out[k] = mergePatch(out[k], v)
+	}
+	return out
+}
+
+// Resolver picks the effective schema of a listing from its mls, merging
+// the base schema with the mls overlay on first use and caching the result
+type Resolver struct {
+	base     *schema.Schema
+	overlays map[string][]byte
+
+	mu    sync.Mutex
+	cache map[string]*schema.Schema
+}
+
+// NewResolver returns a Resolver for base and overlays keyed by mls. mls
+// without an overlay resolve to base
+func NewResolver(base *schema.Schema, overlays map[string][]byte) *Resolver {
+	return &Resolver{
+		base:     base,
+		overlays: overlays,
+		cache:    map[string]*schema.Schema{},
+	}
+}
+
+// Base returns the schema overlays are merged into
+func (r *Resolver) Base() *schema.Schema {
+	return r.base
+}
+
+// For returns the effective schema of mls
+func (r *Resolver) For(mls string) (*schema.Schema, error) {
+	overlay, ok := r.overlays[mls]
+	if !ok {
+		return r.base, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.cache[mls]; ok {
+		return s, nil
+	}
+	s, err := Merge(r.base, overlay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply overlay for mls <%s>: %w", mls, err)
+	}
+	r.cache[mls] = s
+	return s, nil
+}
+
+// Validate validates l against the effective schema of its mls
+func (r *Resolver) Validate(l *approach_3.Listing, rep approach_3.Reporter, coerce bool) error {
+	s, err := r.For(l.Mls)
+	if err != nil {
+		return err
+	}
+	return l.Validate(s, rep, coerce)
+}
+
+// LoadDir reads every <mls>.json file of dir as the overlay of that mls
+func LoadDir(dir string) (map[string][]byte, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	overlays := map[string][]byte{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read overlay <%s>: %w", path, err)
+		}
+		overlays[strings.TrimSuffix(filepath.Base(path), ".json")] = data
+	}
+	return overlays, nil
+}
@@ -0,0 +1,186 @@
+package overlay
+
+import (
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/report"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const base = `{
+	"properties": {
+		"ListPrice": { "type": "number", "maximum": 50000000, "x-severity": "warning" },
+		"Status": { "type": "string", "enum": ["Active", "Closed"] },
+		"Remarks": { "type": "string" }
+	}
+}`
+
+func mustLoad(t *testing.T, data string) *schema.Schema {
+	t.Helper()
+	s, err := schema.Load(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	return s
+}
+
+func TestMerge(t *testing.T) {
+	testCases := []struct {
+		description string
+		overlay     string
+		key         string
+		expected    *schema.Schema
+		expectedErr string
+	}{
+		{
+			description: "it should add properties",
+			overlay:     `{ "properties": { "Pool": { "type": "boolean" } } }`,
+			key:         "Pool",
+			expected:    &schema.Schema{Type: schema.Type{"boolean"}},
+		},
+		{
+			description: "it should tighten a property keyword by keyword",
+			overlay:     `{ "properties": { "ListPrice": { "minimum": 0, "x-severity": "error" } } }`,
+			key:         "ListPrice",
+			expected:    &schema.Schema{Type: schema.Type{"number"}, Minimum: ptr(0), Maximum: ptr(50000000), Severity: "error"},
+		},
+		{
+			description: "it should loosen a property by removing keywords with null",
+			overlay:     `{ "properties": { "ListPrice": { "maximum": null, "x-severity": null } } }`,
+			key:         "ListPrice",
+			expected:    &schema.Schema{Type: schema.Type{"number"}},
+		},
+		{
+			description: "it should replace arrays such as enum as a whole",
+			overlay:     `{ "properties": { "Status": { "enum": ["A", "C"] } } }`,
+			key:         "Status",
+			expected:    &schema.Schema{Type: schema.Type{"string"}, Enum: []any{"A", "C"}},
+		},
+		{
+			description: "it should remove properties set to null",
+			overlay:     `{ "properties": { "Remarks": null } }`,
+			key:         "Remarks",
+		},
+		{
+			description: "it should reject overlays producing an invalid schema",
+			overlay:     `{ "properties": { "ListPrice": { "type": "int" } } }`,
+			expectedErr: "schema does not match the meta-schema",
+		},
+		{
+			description: "it should reject overlays that are not objects",
+			overlay:     `[]`,
+			expectedErr: "overlay must be an object",
+		},
+	}
+
+	for _, testCase := range testCases {
+		b := mustLoad(t, base)
+		merged, err := Merge(b, []byte(testCase.overlay))
+		if testCase.expectedErr != "" {
+			if err == nil || !strings.Contains(err.Error(), testCase.expectedErr) {
+				t.Fatalf("%s: expected error containing <%s>, got <%v>", testCase.description, testCase.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error <%s>", testCase.description, err)
+		}
+		if got := (*merged.Properties)[testCase.key]; !reflect.DeepEqual(got, testCase.expected) {
+			t.Fatalf("%s: expected <%+v>, got <%+v>", testCase.description, testCase.expected, got)
+		}
+		// the base schema must be left untouched
+		if !reflect.DeepEqual(b, mustLoad(t, base)) {
+			t.Fatalf("%s: base schema was modified", testCase.description)
+		}
+	}
+}
+
+func TestResolver(t *testing.T) {
+	b := mustLoad(t, base)
+	r := NewResolver(b, map[string][]byte{
+		"strict": []byte(`{ "properties": { "ListPrice": { "x-severity": "error" } } }`),
+		"broken": []byte(`{ "properties": `),
+	})
+
+	if s, err := r.For("other"); err != nil || s != b {
+		t.Fatalf("expected mls without overlay to resolve to the base schema, got <%v>", err)
+	}
+	strict, err := r.For("strict")
+	if err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	if again, _ := r.For("strict"); again != strict {
+		t.Fatalf("expected the effective schema to be cached")
+	}
+	if _, err := r.For("broken"); err == nil || !strings.Contains(err.Error(), "mls <broken>") {
+		t.Fatalf("expected broken overlay to fail with its mls, got <%v>", err)
+	}
+
+	// the listing mls picks the schema, turning the base warning into an error
+	severities := map[string]report.Severity{}
+	rep := approach_3.ReporterFunc(func(e report.BadKeyVal) error {
+		severities[e.Mls] = e.Severity
+		return nil
+	})
+	for _, mls := range []string{"other", "strict"} {
+		l := approach_3.Listing{Mls: mls, Data: []approach_3.KeyVal{{Key: "ListPrice", Value: 60000000.0}}}
+		_ = r.Validate(&l, rep, false)
+	}
+	expected := map[string]report.Severity{"other": report.SeverityWarning, "strict": report.SeverityError}
+	if !reflect.DeepEqual(severities, expected) {
+		t.Fatalf("expected severities <%v>, got <%v>", expected, severities)
+	}
+}
+
+func TestResolverUnits(t *testing.T) {
+	b := mustLoad(t, `{
+		"properties": {
+			"LivingArea": { "type": "number", "x-unit": "sqft" },
+			"LotSize": { "type": "number", "x-unit": "sqft", "x-unit-from": "LotSizeUnits" },
+			"LotSizeUnits": { "type": "string" }
+		}
+	}`)
+	r := NewResolver(b, map[string][]byte{
+		"canada": []byte(`{ "properties": { "LivingArea": { "x-source-unit": "sqm" } } }`),
+	})
+
+	l := approach_3.Listing{Mls: "canada", Data: []approach_3.KeyVal{
+		{Key: "LivingArea", Value: 100.0},
+		{Key: "LotSize", Value: 0.5},
+		{Key: "LotSizeUnits", Value: "acres"},
+	}}
+	if err := r.Validate(&l, approach_3.ReporterFunc(report.StdOutReporter), false); err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	expected := []approach_3.KeyVal{
+		{Key: "LivingArea", Value: 100 * 10.763910416709722},
+		{Key: "LotSize", Value: 21780.0},
+		{Key: "LotSizeUnits", Value: "acres"},
+	}
+	if !reflect.DeepEqual(l.Data, expected) {
+		t.Fatalf("data <%v> does not match expected <%v>", l.Data, expected)
+	}
+	paths := []string{}
+	for _, e := range l.Audit {
+		paths = append(paths, e.Path)
+	}
+	if !reflect.DeepEqual(paths, []string{"/LivingArea", "/LotSize"}) {
+		t.Fatalf("expected both conversions to be audited, got <%+v>", l.Audit)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	overlays, err := LoadDir("../../../testdata/overlays")
+	if err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	if _, ok := overlays["rets-properties-test"]; !ok || len(overlays) != 1 {
+		t.Fatalf("expected the rets-properties-test overlay, got <%v>", overlays)
+	}
+}
+
+func ptr(f float64) *float64 {
+	return &f
+}
@@ -1,16 +1,213 @@
 package report
 
 import (
+	"bytes"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Severity tells whether a BadKeyVal was rejected or only warned about
+// and kept
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Event tells what a BadKeyVal is reporting
+type Event string
+
+const (
+	// EventInvalid reports a value failing its schema
+	EventInvalid Event = "invalid"
+	// EventUnmapped reports a key without a schema mapping
+	EventUnmapped Event = "unmapped"
+	// EventDeprecated reports a deprecated key still being sent
+	EventDeprecated Event = "deprecated"
 )
 
 type BadKeyVal struct {
-	Key   string
-	Value any
-	Error error
+	DocId    string
+	Mls      string
+	Key      string
+	Value    any
+	Error    error
+	Severity Severity
+	// SchemaVersion identifies the schema that produced the failure
+	SchemaVersion string
+	// SourceKey is the key as sent by the feed when it was matched to Key
+	// through an alias or key folding
+	SourceKey string
+	// Event is EventInvalid when empty
+	Event Event
+}
+
+func (e BadKeyVal) MarshalJSON() ([]byte, error) {
+	var errMsg string
+	if e.Error != nil {
+		errMsg = e.Error.Error()
+	}
+	// error messages quote values in <>, keep them readable
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(struct {
+		DocId    string   `json:"docid,omitempty"`
+		Mls      string   `json:"mls,omitempty"`
+		Key      string   `json:"key"`
+		Source   string   `json:"source_key,omitempty"`
+		Value    any      `json:"value"`
+		Error    string   `json:"error"`
+		Kind     string   `json:"kind"`
+		Event    Event    `json:"event"`
+		Severity Severity `json:"severity,omitempty"`
+		Version  string   `json:"schema_version,omitempty"`
+	}{
+		DocId:    e.DocId,
+		Mls:      e.Mls,
+		Key:      e.Key,
+		Source:   e.SourceKey,
+		Value:    e.Value,
+		Error:    errMsg,
+		Kind:     Kind(e.Error),
+		Event:    e.event(),
+		Severity: e.Severity,
+		Version:  e.SchemaVersion,
+	})
+	return bytes.TrimRight(buf.Bytes(), "\n"), err
+}
+
+func (e BadKeyVal) event() Event {
+	if e.Event == "" {
+		return EventInvalid
+	}
+	return e.Event
+}
+
+// Result is the outcome of validating a single key:value
+type Result string
+
+const (
+	ResultAccepted Result = "accepted"
+	ResultCoerced  Result = "coerced"
+	ResultRejected Result = "rejected"
+	ResultUnmapped Result = "unmapped"
+)
+
+// Outcome describes every validated key:value, good or bad, along with how
+// long its validation took
+type Outcome struct {
+	DocId    string
+	Mls      string
+	Key      string
+	Result   Result
+	Duration time.Duration
+	// SchemaVersion identifies the schema the key:value was validated against
+	SchemaVersion string
+}
+
+// error kinds returned by Kind
+const (
+	KindTypeMismatch  = "type_mismatch"
+	KindCoerce        = "coerce"
+	KindInvalidItems  = "invalid_items"
+	KindInvalidObject = "invalid_object"
+	KindRange         = "range"
+	KindEnum          = "enum"
+	KindRequired      = "required"
+	KindAnyOf         = "any_of"
+	KindDuplicateKey  = "duplicate_key"
+	KindPropertyName  = "property_name"
+	KindUnmapped      = "unmapped"
+	KindTransform     = "transform"
+	KindUnit          = "unit"
+	KindDialect       = "dialect"
+	KindDeprecated    = "deprecated"
+	KindOther         = "other"
+)
+
+// Kind classifies a validation error returned by schema.Schema.Eval.
+// nested failures are classified by their outermost container so a bad
+// array item is an invalid_items error rather than a type mismatch
+func Kind(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, schema.ErrPropertyName):
+		return KindPropertyName
+	case errors.Is(err, schema.ErrUnmapped):
+		return KindUnmapped
+	case errors.Is(err, schema.ErrDeprecated):
+		return KindDeprecated
+	case errors.Is(err, schema.ErrInvalidItems):
+		return KindInvalidItems
+	case errors.Is(err, schema.ErrAnyOf):
+		return KindAnyOf
+	case errors.Is(err, schema.ErrRequired):
+		return KindRequired
+	case errors.Is(err, schema.ErrDuplicateKey):
+		return KindDuplicateKey
+	case errors.Is(err, schema.ErrInvalidObject):
+		return KindInvalidObject
+	case errors.Is(err, schema.ErrTransform):
+		return KindTransform
+	case errors.Is(err, schema.ErrUnit):
+		return KindUnit
+	case errors.Is(err, schema.ErrDialect):
+		return KindDialect
+	case errors.Is(err, schema.ErrCoerce):
+		return KindCoerce
+	case errors.Is(err, schema.ErrTypeMismatch):
+		return KindTypeMismatch
+	case errors.Is(err, schema.ErrRange):
+		return KindRange
+	case errors.Is(err, schema.ErrEnum):
+		return KindEnum
+	default:
+		return KindOther
+	}
 }
 
 func StdOutReporter(e BadKeyVal) error {
+	if e.Severity == SeverityWarning {
+		fmt.Printf("\nwarning for key <%s> with value <%v>: %s\n", e.Key, e.Value, e.Error)
+		return nil
+	}
 	fmt.Printf("\nfailed to validate key <%s> with value <%v>: %s\n", e.Key, e.Value, e.Error)
 	return nil
 }
+
+// NewTextReporter returns a reporter func writing one human readable
+// line per BadKeyVal to w
+func NewTextReporter(w io.Writer) func(e BadKeyVal) error {
+	var mu sync.Mutex
+	return func(e BadKeyVal) error {
+		severity := e.Severity
+		if severity == "" {
+			severity = SeverityError
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_, err := fmt.Fprintf(w, "%s: mls <%s> docid <%s> key <%s> with value <%v>: %s\n", severity, e.Mls, e.DocId, e.Key, e.Value, e.Error)
+		return err
+	}
+}
+
+// NewJSONReporter returns a reporter func writing one json object per
+// BadKeyVal to w
+func NewJSONReporter(w io.Writer) func(e BadKeyVal) error {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return func(e BadKeyVal) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return enc.Encode(e)
+	}
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"cmenke/go-playground/lib/approach_3/schema"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestKind(t *testing.T) {
+	testCases := []struct {
+		description  string
+		err          error
+		expectedKind string
+	}{
+		{
+			description:  "it should classify no error as no kind",
+			err:          nil,
+			expectedKind: "",
+		},
+		{
+			description:  "it should classify a type mismatch",
+			err:          fmt.Errorf("%w, bad", schema.ErrTypeMismatch),
+			expectedKind: KindTypeMismatch,
+		},
+		{
+			description:  "it should classify a failed unit conversion",
+			err:          fmt.Errorf("%w, unknown unit <furlong>", schema.ErrUnit),
+			expectedKind: KindUnit,
+		},
+		{
+			description:  "it should classify an unsupported dialect",
+			err:          fmt.Errorf("%w <example.com/schema>", schema.ErrDialect),
+			expectedKind: KindDialect,
+		},
+		{
+			description:  "it should classify a bad item by its array",
+			err:          errors.Join(fmt.Errorf("%w: [a]", schema.ErrInvalidItems), fmt.Errorf("%w, bad", schema.ErrUnit)),
+			expectedKind: KindInvalidItems,
+		},
+		{
+			description:  "it should classify unknown errors as other",
+			err:          errors.New("boom"),
+			expectedKind: KindOther,
+		},
+	}
+
+	for _, testCase := range testCases {
+		if kind := Kind(testCase.err); kind != testCase.expectedKind {
+			t.Fatalf("%s: expected kind <%s>, got <%s>", testCase.description, testCase.expectedKind, kind)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package report
+
+import (
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// StatsKey identifies one aggregation bucket of the StatsReporter
+type StatsKey struct {
+	Mls          string   `json:"mls"`
+	Key          string   `json:"key"`
+	Kind         string   `json:"kind"`
+	Severity     Severity `json:"severity"`
+	ObservedType string   `json:"observed_type"`
+}
+
+// KeyStats is the aggregated view of every BadKeyVal that fell into
+// the same StatsKey bucket
+type KeyStats struct {
+	StatsKey
+	Count     int       `json:"count"`
+	Samples   []any     `json:"samples"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// StatsReporter aggregates reported BadKeyVals by mls, key, error kind,
+// severity and observed value type. it is safe for concurrent use so a
+// single instance can be shared by every worker of a batch
+type StatsReporter struct {
+	mu         sync.Mutex
+	maxSamples int
+	buckets    map[StatsKey]*KeyStats
+	now        func() time.Time
+}
+
+// NewStatsReporter returns a StatsReporter keeping up to maxSamples
+// distinct sample values per bucket
+func NewStatsReporter(maxSamples int) *StatsReporter {
+	return &StatsReporter{
+		maxSamples: maxSamples,
+		buckets:    map[StatsKey]*KeyStats{},
+		now:        time.Now,
+	}
+}
+
+func (sr *StatsReporter) Report(e BadKeyVal) error {
+	k := StatsKey{
+		Mls:          e.Mls,
+		Key:          e.Key,
+		Kind:         Kind(e.Error),
+		Severity:     e.Severity,
+		ObservedType: schema.GetDataType(e.Value),
+	}
+	now := sr.now()
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	b, ok := sr.buckets[k]
+	if !ok {
+		b = &KeyStats{StatsKey: k, FirstSeen: now}
+		sr.buckets[k] = b
+	}
+	b.Count++
+	b.LastSeen = now
+	if len(b.Samples) < sr.maxSamples && !containsSample(b.Samples, e.Value) {
+		b.Samples = append(b.Samples, e.Value)
+	}
+	return nil
+}
+
+func containsSample(samples []any, v any) bool {
+	for _, s := range samples {
+		if fmt.Sprint(s) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Snapshot returns a copy of every bucket ordered by descending count,
+// then by mls and key
+func (sr *StatsReporter) Snapshot() []KeyStats {
+	sr.mu.Lock()
+	out := make([]KeyStats, 0, len(sr.buckets))
+	for _, b := range sr.buckets {
+		c := *b
+		c.Samples = append([]any(nil), b.Samples...)
+		out = append(out, c)
+	}
+	sr.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		if out[i].Mls != out[j].Mls {
+			return out[i].Mls < out[j].Mls
+		}
+		if out[i].Key != out[j].Key {
+			return out[i].Key < out[j].Key
+		}
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		if out[i].Severity != out[j].Severity {
+			return out[i].Severity < out[j].Severity
+		}
+		return out[i].ObservedType < out[j].ObservedType
+	})
+	return out
+}
+
+// Reset clears all aggregated stats, e.g. at the start of a new batch
+func (sr *StatsReporter) Reset() {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.buckets = map[StatsKey]*KeyStats{}
+}
+
+// WriteJSON writes the current snapshot as a json array
+func (sr *StatsReporter) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(sr.Snapshot())
+}
+
+// WriteTable writes the current snapshot as a human readable summary table
+func (sr *StatsReporter) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "MLS\tKEY\tKIND\tSEVERITY\tTYPE\tCOUNT\tFIRST SEEN\tLAST SEEN\tSAMPLES")
+	for _, s := range sr.Snapshot() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%v\n",
+			s.Mls,
+			s.Key,
+			s.Kind,
+			s.Severity,
+			s.ObservedType,
+			s.Count,
+			s.FirstSeen.Format(time.RFC3339),
+			s.LastSeen.Format(time.RFC3339),
+			s.Samples,
+		)
+	}
+	return tw.Flush()
+}
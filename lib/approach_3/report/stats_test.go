@@ -0,0 +1,85 @@
+package report
+
+import (
+	"cmenke/go-playground/lib/approach_3/schema"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStatsReporter(t *testing.T) {
+	typeErr := fmt.Errorf("%w: bad", schema.ErrTypeMismatch)
+	itemsErr := errors.Join(fmt.Errorf("%w: [a 10]", schema.ErrInvalidItems), typeErr)
+
+	sr := NewStatsReporter(2)
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sr.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	input := []BadKeyVal{
+		{Mls: "a", Key: "ListPrice", Value: "abc", Error: typeErr, Severity: SeverityError},
+		{Mls: "a", Key: "ListPrice", Value: "def", Error: typeErr, Severity: SeverityError},
+		{Mls: "a", Key: "ListPrice", Value: "abc", Error: typeErr, Severity: SeverityError},
+		{Mls: "a", Key: "ListPrice", Value: "ghi", Error: typeErr, Severity: SeverityError},
+		{Mls: "b", Key: "ListPrice", Value: true, Error: typeErr, Severity: SeverityError},
+		{Mls: "a", Key: "Appliances", Value: []any{"a", 10}, Error: itemsErr, Severity: SeverityError},
+	}
+	for _, e := range input {
+		if err := sr.Report(e); err != nil {
+			t.Fatalf("unexpected report error: %s", err)
+		}
+	}
+
+	expected := []KeyStats{
+		{
+			StatsKey:  StatsKey{Mls: "a", Key: "ListPrice", Kind: KindTypeMismatch, Severity: SeverityError, ObservedType: "string"},
+			Count:     4,
+			Samples:   []any{"abc", "def"},
+			FirstSeen: time.Date(2025, 1, 1, 0, 0, 1, 0, time.UTC),
+			LastSeen:  time.Date(2025, 1, 1, 0, 0, 4, 0, time.UTC),
+		},
+		{
+			StatsKey:  StatsKey{Mls: "a", Key: "Appliances", Kind: KindInvalidItems, Severity: SeverityError, ObservedType: "array"},
+			Count:     1,
+			Samples:   []any{[]any{"a", 10}},
+			FirstSeen: time.Date(2025, 1, 1, 0, 0, 6, 0, time.UTC),
+			LastSeen:  time.Date(2025, 1, 1, 0, 0, 6, 0, time.UTC),
+		},
+		{
+			StatsKey:  StatsKey{Mls: "b", Key: "ListPrice", Kind: KindTypeMismatch, Severity: SeverityError, ObservedType: "boolean"},
+			Count:     1,
+			Samples:   []any{true},
+			FirstSeen: time.Date(2025, 1, 1, 0, 0, 5, 0, time.UTC),
+			LastSeen:  time.Date(2025, 1, 1, 0, 0, 5, 0, time.UTC),
+		},
+	}
+
+	if snapshot := sr.Snapshot(); !reflect.DeepEqual(snapshot, expected) {
+		t.Fatalf("snapshot <%+v> does not match expected <%+v>", snapshot, expected)
+	}
+}
+
+func TestStatsReporterConcurrent(t *testing.T) {
+	sr := NewStatsReporter(1)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = sr.Report(BadKeyVal{Mls: "a", Key: "ListPrice", Value: j, Error: schema.ErrTypeMismatch})
+			}
+		}()
+	}
+	wg.Wait()
+
+	snapshot := sr.Snapshot()
+	if len(snapshot) != 1 || snapshot[0].Count != 800 {
+		t.Fatalf("expected a single bucket with 800 reports, got <%+v>", snapshot)
+	}
+}
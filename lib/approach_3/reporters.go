@@ -0,0 +1,145 @@
+package approach_3
+
+import (
+	"cmenke/go-playground/lib/approach_3/report"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// now is swapped out in tests to control time based combinators
+var now = time.Now
+
+// Multi fans every report out to all of reporters, joining any errors
+// they return. outcomes are forwarded to the reporters that are Observers
+func Multi(reporters ...Reporter) Reporter {
+	return multiReporter(reporters)
+}
+
+type multiReporter []Reporter
+
+func (m multiReporter) Report(e report.BadKeyVal) error {
+	errs := []error{}
+	for _, r := range m {
+		if err := r.Report(e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiReporter) Observe(o report.Outcome) {
+	for _, r := range m {
+		if ob, ok := r.(Observer); ok {
+			ob.Observe(o)
+		}
+	}
+}
+
+// gatedReporter only forwards the reports allowed by allow. outcomes are
+// always forwarded since they are not failures
+type gatedReporter struct {
+	next  Reporter
+	allow func(e report.BadKeyVal) bool
+}
+
+func (g *gatedReporter) Report(e report.BadKeyVal) error {
+	if !g.allow(e) {
+		return nil
+	}
+	return g.next.Report(e)
+}
+
+func (g *gatedReporter) Observe(o report.Outcome) {
+	if ob, ok := g.next.(Observer); ok {
+		ob.Observe(o)
+	}
+}
+
+// Filter only forwards the reports pred returns true for
+func Filter(pred func(e report.BadKeyVal) bool, r Reporter) Reporter {
+	return &gatedReporter{next: r, allow: pred}
+}
+
+// Sample forwards a random fraction, between 0 and 1, of reports to r
+func Sample(rate float64, r Reporter) Reporter {
+	return &gatedReporter{
+		next: r,
+		allow: func(report.BadKeyVal) bool {
+			return rand.Float64() < rate
+		},
+	}
+}
+
+// Rate is a number of events allowed per time window
+type Rate struct {
+	N   int
+	Per time.Duration
+}
+
+// RateLimit forwards at most perKey.N reports for each mls and key within
+// every perKey.Per window, dropping the rest
+func RateLimit(perKey Rate, r Reporter) Reporter {
+	type window struct {
+		start time.Time
+		count int
+	}
+	var mu sync.Mutex
+	windows := map[[2]string]*window{}
+
+	return &gatedReporter{
+		next: r,
+		allow: func(e report.BadKeyVal) bool {
+			t := now()
+			k := [2]string{e.Mls, e.Key}
+
+			mu.Lock()
+			defer mu.Unlock()
+			w, ok := windows[k]
+			if !ok || t.Sub(w.start) >= perKey.Per {
+				w = &window{start: t}
+				windows[k] = w
+			}
+			if w.count >= perKey.N {
+				return false
+			}
+			w.count++
+			return true
+		},
+	}
+}
+
+// Dedup drops reports identical to one already forwarded within window.
+// reports are identical when their mls, key, value and error match
+func Dedup(window time.Duration, r Reporter) Reporter {
+	var mu sync.Mutex
+	seen := map[string]time.Time{}
+	lastSweep := now()
+
+	return &gatedReporter{
+		next: r,
+		allow: func(e report.BadKeyVal) bool {
+			t := now()
+			k := fmt.Sprintf("%s\x00%s\x00%v\x00%v", e.Mls, e.Key, e.Value, e.Error)
+
+			mu.Lock()
+			defer mu.Unlock()
+			// forget expired reports once per window so seen does not grow forever
+			if t.Sub(lastSweep) >= window {
+				for sk, st := range seen {
+					if t.Sub(st) >= window {
+						delete(seen, sk)
+					}
+				}
+				lastSweep = t
+			}
+			if st, ok := seen[k]; ok && t.Sub(st) < window {
+				return false
+			}
+			seen[k] = t
+			return true
+		},
+	}
+}
@@ -0,0 +1,153 @@
+package approach_3
+
+import (
+	"cmenke/go-playground/lib/approach_3/report"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type countingReporter struct {
+	reports  []report.BadKeyVal
+	outcomes []report.Outcome
+	err      error
+}
+
+func (c *countingReporter) Report(e report.BadKeyVal) error {
+	c.reports = append(c.reports, e)
+	return c.err
+}
+
+func (c *countingReporter) Observe(o report.Outcome) {
+	c.outcomes = append(c.outcomes, o)
+}
+
+func TestReporterCombinators(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	price := report.BadKeyVal{Mls: "a", Key: "ListPrice", Value: "abc", Error: schema.ErrTypeMismatch}
+	remarks := report.BadKeyVal{Mls: "a", Key: "Remarks", Value: 1, Error: schema.ErrTypeMismatch}
+
+	testCases := []struct {
+		description string
+		build       func(next Reporter) Reporter
+		input       []report.BadKeyVal
+		step        time.Duration
+		expected    int
+	}{
+		{
+			description: "it should forward only the reports matching a filter",
+			build: func(next Reporter) Reporter {
+				return Filter(func(e report.BadKeyVal) bool { return e.Key == "ListPrice" }, next)
+			},
+			input:    []report.BadKeyVal{price, remarks, price},
+			expected: 2,
+		},
+		{
+			description: "it should forward every report when sampling at 1",
+			build:       func(next Reporter) Reporter { return Sample(1, next) },
+			input:       []report.BadKeyVal{price, remarks, price},
+			expected:    3,
+		},
+		{
+			description: "it should forward no report when sampling at 0",
+			build:       func(next Reporter) Reporter { return Sample(0, next) },
+			input:       []report.BadKeyVal{price, remarks, price},
+			expected:    0,
+		},
+		{
+			description: "it should rate limit reports per key",
+			build: func(next Reporter) Reporter {
+				return RateLimit(Rate{N: 1, Per: time.Minute}, next)
+			},
+			input:    []report.BadKeyVal{price, price, remarks, price},
+			expected: 2,
+		},
+		{
+			description: "it should allow reports again once the rate limit window passed",
+			build: func(next Reporter) Reporter {
+				return RateLimit(Rate{N: 1, Per: time.Minute}, next)
+			},
+			input:    []report.BadKeyVal{price, price, price},
+			step:     time.Minute,
+			expected: 3,
+		},
+		{
+			description: "it should drop duplicate reports within the dedup window",
+			build:       func(next Reporter) Reporter { return Dedup(time.Minute, next) },
+			input:       []report.BadKeyVal{price, price, remarks, price},
+			step:        time.Second,
+			expected:    2,
+		},
+		{
+			description: "it should fan reports out to every reporter",
+			build: func(next Reporter) Reporter {
+				return Multi(next, Filter(func(report.BadKeyVal) bool { return true }, next))
+			},
+			input:    []report.BadKeyVal{price, remarks},
+			expected: 4,
+		},
+	}
+
+	for _, testCase := range testCases {
+		next := &countingReporter{}
+		r := testCase.build(next)
+		for _, e := range testCase.input {
+			if err := r.Report(e); err != nil {
+				t.Fatalf("%s: unexpected error: %s", testCase.description, err)
+			}
+			clock = clock.Add(testCase.step)
+		}
+		if len(next.reports) != testCase.expected {
+			t.Fatalf("%s: expected %d reports, got %d", testCase.description, testCase.expected, len(next.reports))
+		}
+	}
+}
+
+func TestReporterErrorsAreHonored(t *testing.T) {
+	var s schema.Schema
+	err := json.Unmarshal([]byte(`{ "properties": { "ListPrice": { "type": "number" } } }`), &s)
+	if err != nil {
+		t.Fatalf("failed to unmarshal schema: %s", err)
+	}
+
+	reportErr := errors.New("alert channel is down")
+	failing := &countingReporter{err: reportErr}
+	observer := &countingReporter{}
+	r := Multi(Filter(func(e report.BadKeyVal) bool { return e.Key == "ListPrice" }, failing), observer)
+
+	kv := KeyVal{Key: "ListPrice", Value: "abc"}
+	_, err = kv.Validate(&s, r, false)
+	if !errors.Is(err, reportErr) {
+		t.Fatalf("expected validate error to contain reporter error, got <%v>", err)
+	}
+	if !errors.Is(err, schema.ErrTypeMismatch) {
+		t.Fatalf("expected validate error to contain validation error, got <%v>", err)
+	}
+	if len(observer.outcomes) != 1 || observer.outcomes[0].Result != report.ResultRejected {
+		t.Fatalf("expected outcome to be forwarded through combinators, got <%+v>", observer.outcomes)
+	}
+}
+
+func TestReporterErrorsKeepValidValues(t *testing.T) {
+	var s schema.Schema
+	err := json.Unmarshal([]byte(`{ "properties": { "ListPrice": { "type": "number", "maximum": 100, "x-severity": "warning" } } }`), &s)
+	if err != nil {
+		t.Fatalf("failed to unmarshal schema: %s", err)
+	}
+
+	reportErr := errors.New("alert channel is down")
+	l := Listing{Data: []KeyVal{{Key: "ListPrice", Value: 1000.0}}}
+	err = l.Validate(&s, &countingReporter{err: reportErr}, false)
+	if !errors.Is(err, reportErr) {
+		t.Fatalf("expected validate error to contain reporter error, got <%v>", err)
+	}
+	if !reflect.DeepEqual(l.Data, []KeyVal{{Key: "ListPrice", Value: 1000.0}}) {
+		t.Fatalf("expected the warned value to be kept, got <%v>", l.Data)
+	}
+}
@@ -0,0 +1,50 @@
+package schema
+
+import "fmt"
+
+// Annotation holds the annotation keywords of a schema a value is valid
+// against
+type Annotation struct {
+	Path        string `json:"path"` // json pointer to the annotated value
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Examples    []any  `json:"examples,omitempty"`
+	Deprecated  bool   `json:"deprecated,omitempty"`
+	ReadOnly    bool   `json:"readOnly,omitempty"`
+}
+
+// annotate passes the annotations of s, if it has any, to opts.Annotate
+func (s *Schema) annotate(opts EvalOptions) {
+	if opts.Annotate == nil {
+		return
+	}
+	if s.Title == "" && s.Description == "" && s.Examples == nil && !s.Deprecated && !s.ReadOnly {
+		return
+	}
+	opts.Annotate(Annotation{
+		Path:        opts.Path,
+		Title:       s.Title,
+		Description: s.Description,
+		Examples:    s.Examples,
+		Deprecated:  s.Deprecated,
+		ReadOnly:    s.ReadOnly,
+	})
+}
+
+// deprecated returns the warning for sending key when one of the schemas
+// it is evaluated against is deprecated
+func deprecated(key string, schemas []*Schema) error {
+	for _, s := range schemas {
+		if s != nil && s.Deprecated {
+			return fmt.Errorf("%w <%s>, it is still being sent", ErrDeprecated, key)
+		}
+	}
+	return nil
+}
+
+// EvalDeprecated returns an error wrapping ErrDeprecated when key is a
+// deprecated property of s, directly or through a matching pattern
+func (s *Schema) EvalDeprecated(key string) error {
+	name, schemas, _ := s.Schemas(key)
+	return deprecated(name, schemas)
+}
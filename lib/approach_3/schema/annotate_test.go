@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnnotations(t *testing.T) {
+	testCases := []struct {
+		description string
+		schema      string
+		doc         string
+		expected    map[string][]Annotation
+	}{
+		{
+			description: "it should collect annotations by instance location",
+			schema: `{
+				"title": "Agent",
+				"properties": {
+					"Email": { "type": "string", "description": "primary contact", "examples": [ "a@b.com" ] },
+					"Offices": { "type": "array", "items": { "type": "string", "readOnly": true } }
+				}
+			}`,
+			doc: `{ "Email": "a@b.com", "Offices": [ "A1", "B2" ] }`,
+			expected: map[string][]Annotation{
+				"":           {{Path: "", Title: "Agent"}},
+				"/Email":     {{Path: "/Email", Description: "primary contact", Examples: []any{"a@b.com"}}},
+				"/Offices/0": {{Path: "/Offices/0", ReadOnly: true}},
+				"/Offices/1": {{Path: "/Offices/1", ReadOnly: true}},
+			},
+		},
+		{
+			description: "it should not collect annotations of schemas the value fails",
+			schema:      `{ "properties": { "Email": { "type": "string", "title": "Email" }, "Phone": { "type": "string", "title": "Phone" } } }`,
+			doc:         `{ "Email": 1, "Phone": "555" }`,
+			expected: map[string][]Annotation{
+				"/Phone": {{Path: "/Phone", Title: "Phone"}},
+			},
+		},
+		{
+			description: "it should only collect annotations of the matching anyOf branch",
+			schema: `{ "anyOf": [
+				{ "type": "object", "title": "office", "properties": { "OfficeId": { "title": "office id" } }, "required": [ "OfficeKey" ] },
+				{ "type": "object", "title": "agent", "properties": { "OfficeId": { "title": "agent office" } } }
+			] }`,
+			doc: `{ "OfficeId": "A1" }`,
+			expected: map[string][]Annotation{
+				"":          {{Path: "", Title: "agent"}},
+				"/OfficeId": {{Path: "/OfficeId", Title: "agent office"}},
+			},
+		},
+		{
+			description: "it should collect annotations of every schema applying to a location",
+			schema: `{
+				"$defs": { "price": { "type": "number", "description": "in dollars" } },
+				"properties": { "ListPrice": { "$ref": "#/$defs/price", "title": "List price" } }
+			}`,
+			doc: `{ "ListPrice": 5 }`,
+			expected: map[string][]Annotation{
+				"/ListPrice": {{Path: "/ListPrice", Description: "in dollars"}, {Path: "/ListPrice", Title: "List price"}},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		s, err := Load(strings.NewReader(testCase.schema))
+		if err != nil {
+			t.Fatalf("%s: failed to load schema: %s", testCase.description, err)
+		}
+		res, err := Validate([]byte(testCase.doc), s)
+		if err != nil {
+			t.Fatalf("%s: unexpected error <%s>", testCase.description, err)
+		}
+		if !reflect.DeepEqual(res.Annotations, testCase.expected) {
+			t.Fatalf("%s: annotations <%+v> do not match expected <%+v>", testCase.description, res.Annotations, testCase.expected)
+		}
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	s, err := Load(strings.NewReader(`{
+		"properties": { "Geo": { "type": "object", "properties": { "County": { "type": "string", "deprecated": true } } } },
+		"patternProperties": { "^Old": { "deprecated": true } }
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+
+	// nested deprecated properties are kept with a warning
+	v := map[string]any{"Geo": map[string]any{"County": "Travis"}}
+	out, err := s.Eval(v, false)
+	if !IsWarning(err) || !errors.Is(err, ErrDeprecated) {
+		t.Fatalf("expected a deprecation warning, got <%v>", err)
+	}
+	if !reflect.DeepEqual(out, v) {
+		t.Fatalf("expected the value to be kept, got <%v>", out)
+	}
+
+	// a deprecated property failing its schema is an error as usual
+	if _, err := s.Eval(map[string]any{"Geo": map[string]any{"County": 1.0}}, false); err == nil || IsWarning(err) {
+		t.Fatalf("expected an error, got <%v>", err)
+	}
+
+	if err := s.EvalDeprecated("OldPrice"); !errors.Is(err, ErrDeprecated) {
+		t.Fatalf("expected <OldPrice> to be deprecated through its pattern, got <%v>", err)
+	}
+	if err := s.EvalDeprecated("Geo"); err != nil {
+		t.Fatalf("expected <Geo> not to be deprecated, got <%v>", err)
+	}
+}
@@ -0,0 +1,113 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// severities a schema node can be annotated with via `x-severity`. the
+// severity applies to the node's value assertions (minimum, maximum, enum),
+// a value of the wrong type is always an error
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Warning is returned by Eval when the only violations found were on nodes
+// annotated with `x-severity: warning`. the value returned along it is
+// valid and should be kept
+type Warning struct {
+	Err error
+}
+
+func (w *Warning) Error() string {
+	return fmt.Sprintf("warning: %s", w.Err)
+}
+
+func (w *Warning) Unwrap() error {
+	return w.Err
+}
+
+// IsWarning reports whether err is a warning only error returned by Eval.
+// an error joining a warning with a real violation is not a warning
+func IsWarning(err error) bool {
+	_, ok := err.(*Warning)
+	return ok
+}
+
+// evalAssertions checks the value level keywords of s against val
+func evalAssertions(s *Schema, val any) error {
+	errs := []error{}
+
+	if n, ok := toFloat(val); ok {
+		if s.Minimum != nil && n < *s.Minimum {
+			errs = append(errs, fmt.Errorf("%w, the value <%v> is less than the minimum <%v>", ErrRange, val, *s.Minimum))
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			errs = append(errs, fmt.Errorf("%w, the value <%v> is greater than the maximum <%v>", ErrRange, val, *s.Maximum))
+		}
+		if s.ExclusiveMinimum != nil && n <= *s.ExclusiveMinimum {
+			errs = append(errs, fmt.Errorf("%w, the value <%v> is not greater than the exclusive minimum <%v>", ErrRange, val, *s.ExclusiveMinimum))
+		}
+		if s.ExclusiveMaximum != nil && n >= *s.ExclusiveMaximum {
+			errs = append(errs, fmt.Errorf("%w, the value <%v> is not less than the exclusive maximum <%v>", ErrRange, val, *s.ExclusiveMaximum))
+		}
+	}
+
+	if s.Enum != nil && !inEnum(s.Enum, val) {
+		errs = append(errs, fmt.Errorf("%w, the value <%v> is not one of <%v>", ErrEnum, val, s.Enum))
+	}
+
+	return errors.Join(errs...)
+}
+
+// inEnum reports whether val equals one of the enum values. numbers are
+// compared by value so a coerced int matches a float64 from the schema
+func inEnum(enum []any, val any) bool {
+	n, isNum := toFloat(val)
+	for _, e := range enum {
+		if en, ok := toFloat(e); ok && isNum {
+			if en == n {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, val) {
+			return true
+		}
+	}
+	return false
+}
+
+// toFloat returns val as a float64 if it is of any numeric type
+func toFloat(val any) (float64, bool) {
+	switch n := val.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
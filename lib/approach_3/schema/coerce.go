@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Coercer converts a stringified value into a type, returning an error
+// when the string does not hold a value of that type
+type Coercer func(v string) (any, error)
+
+var (
+	coercersMu sync.RWMutex
+	coercers   = map[string]Coercer{
+		"null":    coerceNull,
+		"boolean": coerceBoolean,
+		"integer": coerceInteger,
+		"number":  coerceNumber,
+		"array":   coerceArray,
+		"object":  coerceObject,
+	}
+)
+
+// RegisterCoercer sets the coercer used for typeName when coercing
+// strings, replacing the built-in one. feeds sending booleans as Y and N
+// can be handled by registering a boolean coercer for them
+func RegisterCoercer(typeName string, c Coercer) {
+	coercersMu.Lock()
+	defer coercersMu.Unlock()
+	coercers[typeName] = c
+}
+
+func lookupCoercer(typeName string) (Coercer, bool) {
+	coercersMu.RLock()
+	defer coercersMu.RUnlock()
+	c, ok := coercers[typeName]
+	return c, ok
+}
+
+// if we want value to be null, just set to nil
+func coerceNull(v string) (any, error) {
+	return nil, nil
+}
+
+// only parse stringifed true and false
+func coerceBoolean(v string) (any, error) {
+	if v == "true" {
+		return true, nil
+	} else if v == "false" {
+		return false, nil
+	}
+	return nil, fmt.Errorf("failed to parse value <%s> to boolean", v)
+}
+
+func coerceInteger(v string) (any, error) {
+	intVal, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse value <%s> to integer: %s", v, err)
+	}
+	return intVal, nil
+}
+
+// try to parse integer first as it is more specific than number
+func coerceNumber(v string) (any, error) {
+	intVal, intErr := coerceInteger(v)
+	if intErr == nil {
+		return intVal, nil
+	}
+	floatVal, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to parse value <%s> to float: %s", v, err), intErr)
+	}
+	return floatVal, nil
+}
+
+func coerceArray(v string) (any, error) {
+	var arr []any
+	if err := json.Unmarshal([]byte(v), &arr); err != nil {
+		return nil, fmt.Errorf("failed to parse value <%s> to array: %s", v, err)
+	}
+	return arr, nil
+}
+
+func coerceObject(v string) (any, error) {
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(v), &obj); err != nil {
+		return nil, fmt.Errorf("failed to parse value <%s> to obj: %s", v, err)
+	}
+	return obj, nil
+}
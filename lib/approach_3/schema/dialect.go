@@ -0,0 +1,170 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// drafts of json schema, by their $schema uri without scheme or fragment
+const (
+	Draft04     = "json-schema.org/draft-04/schema"
+	Draft06     = "json-schema.org/draft-06/schema"
+	Draft07     = "json-schema.org/draft-07/schema"
+	Draft201909 = "json-schema.org/draft/2019-09/schema"
+	Draft202012 = "json-schema.org/draft/2020-12/schema"
+)
+
+// dialects lists the changes needed to bring a draft to 2020-12
+var dialects = map[string]struct {
+	// booleanExclusive drafts make exclusiveMinimum and exclusiveMaximum
+	// booleans modifying minimum and maximum
+	booleanExclusive bool
+	// tupleItems drafts allow items to be an array, with additionalItems
+	// applying to the items after it
+	tupleItems bool
+	// definitions drafts keep subschemas in definitions rather than $defs
+	definitions bool
+	// refOverrides drafts ignore every keyword next to $ref
+	refOverrides bool
+}{
+	Draft04:     {booleanExclusive: true, tupleItems: true, definitions: true, refOverrides: true},
+	Draft06:     {tupleItems: true, definitions: true, refOverrides: true},
+	Draft07:     {tupleItems: true, definitions: true, refOverrides: true},
+	Draft201909: {tupleItems: true},
+	Draft202012: {},
+}
+
+// DialectOf returns the draft a $schema uri refers to, documents without
+// one are 2020-12. http and https uris are the same, as are uris with and
+// without an empty fragment
+func DialectOf(uri string) (string, error) {
+	if uri == "" {
+		return Draft202012, nil
+	}
+	draft := strings.TrimSuffix(uri, "#")
+	draft = strings.TrimPrefix(strings.TrimPrefix(draft, "http://"), "https://")
+	if _, ok := dialects[draft]; !ok {
+		return "", fmt.Errorf("%w <%s>", ErrDialect, uri)
+	}
+	return draft, nil
+}
+
+// normalize rewrites a raw schema document of an older draft, according
+// to its root $schema, into the 2020-12 keywords Schema is made of. it
+// reports whether anything was rewritten
+func normalize(doc any) (any, bool, error) {
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return doc, false, nil
+	}
+	uri, _ := root["$schema"].(string)
+	draft, err := DialectOf(uri)
+	if err != nil {
+		return doc, false, err
+	}
+	if draft == Draft202012 {
+		return doc, false, nil
+	}
+	n := normalizer{draft: draft}
+	n.node(root)
+	return root, n.changed, nil
+}
+
+type normalizer struct {
+	draft   string
+	changed bool
+}
+
+// node rewrites the keywords of a schema object in place, then the
+// schemas it holds
+func (n *normalizer) node(v any) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return
+	}
+	d := dialects[n.draft]
+
+	if d.refOverrides {
+		if _, ok := obj["$ref"]; ok {
+			for k := range obj {
+				// definitions are kept as they are the target of refs
+				switch k {
+				case "$ref", "$schema", "$defs", "definitions":
+				default:
+					delete(obj, k)
+					n.changed = true
+				}
+			}
+		}
+	}
+	if ref, ok := obj["$ref"].(string); ok && d.definitions && strings.HasPrefix(ref, "#/definitions/") {
+		obj["$ref"] = "#/$defs/" + strings.TrimPrefix(ref, "#/definitions/")
+		n.changed = true
+	}
+	if d.booleanExclusive {
+		n.exclusive(obj, "exclusiveMinimum", "minimum")
+		n.exclusive(obj, "exclusiveMaximum", "maximum")
+	}
+	if d.tupleItems {
+		if items, ok := obj["items"].([]any); ok {
+			obj["prefixItems"] = items
+			delete(obj, "items")
+			if additional, ok := obj["additionalItems"]; ok {
+				obj["items"] = additional
+			}
+			n.changed = true
+		}
+		// additionalItems has no effect unless items is an array
+		if _, ok := obj["additionalItems"]; ok {
+			delete(obj, "additionalItems")
+			n.changed = true
+		}
+	}
+	if defs, ok := obj["definitions"].(map[string]any); ok && d.definitions {
+		merged, _ := obj["$defs"].(map[string]any)
+		if merged == nil {
+			merged = map[string]any{}
+		}
+		for k, def := range defs {
+			if _, ok := merged[k]; !ok {
+				merged[k] = def
+			}
+		}
+		obj["$defs"] = merged
+		delete(obj, "definitions")
+		n.changed = true
+	}
+
+	for _, k := range []string{"items", "additionalProperties", "propertyNames"} {
+		n.node(obj[k])
+	}
+	for _, k := range []string{"anyOf", "prefixItems"} {
+		if arr, ok := obj[k].([]any); ok {
+			for _, sub := range arr {
+				n.node(sub)
+			}
+		}
+	}
+	for _, k := range []string{"properties", "patternProperties", "$defs"} {
+		if m, ok := obj[k].(map[string]any); ok {
+			for _, sub := range m {
+				n.node(sub)
+			}
+		}
+	}
+}
+
+// exclusive turns a boolean exclusive keyword into the number bound it
+// modifies
+func (n *normalizer) exclusive(obj map[string]any, exclusive, bound string) {
+	flag, ok := obj[exclusive].(bool)
+	if !ok {
+		return
+	}
+	delete(obj, exclusive)
+	if v, ok := obj[bound]; ok && flag {
+		obj[exclusive] = v
+		delete(obj, bound)
+	}
+	n.changed = true
+}
@@ -0,0 +1,102 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadDialects(t *testing.T) {
+	testCases := []struct {
+		description string
+		schema      string
+		// equivalent is the 2020-12 schema it should behave like
+		equivalent string
+		inputs     []any
+	}{
+		{
+			description: "it should turn draft-04 boolean exclusive bounds into numbers",
+			schema:      `{ "$schema": "http://json-schema.org/draft-04/schema#", "type": "number", "minimum": 0, "exclusiveMinimum": true, "maximum": 10, "exclusiveMaximum": false }`,
+			equivalent:  `{ "type": "number", "exclusiveMinimum": 0, "maximum": 10 }`,
+			inputs:      []any{-1.0, 0.0, 0.5, 10.0, 10.5},
+		},
+		{
+			description: "it should keep draft-07 exclusive bounds",
+			schema:      `{ "$schema": "http://json-schema.org/draft-07/schema#", "type": "number", "exclusiveMaximum": 10 }`,
+			equivalent:  `{ "type": "number", "exclusiveMaximum": 10 }`,
+			inputs:      []any{9.5, 10.0},
+		},
+		{
+			description: "it should turn tuple items into prefixItems",
+			schema:      `{ "$schema": "http://json-schema.org/draft-07/schema#", "type": "array", "items": [ { "type": "number" }, { "type": "string" } ], "additionalItems": { "type": "boolean" } }`,
+			equivalent:  `{ "type": "array", "prefixItems": [ { "type": "number" }, { "type": "string" } ], "items": { "type": "boolean" } }`,
+			inputs:      []any{[]any{1.0, "a"}, []any{1.0, "a", true}, []any{"a", 1.0}, []any{1.0, "a", 2.0}},
+		},
+		{
+			description: "it should ignore additionalItems next to a single items schema",
+			schema:      `{ "$schema": "https://json-schema.org/draft/2019-09/schema", "items": { "type": "number" }, "additionalItems": { "type": "string" } }`,
+			equivalent:  `{ "items": { "type": "number" } }`,
+			inputs:      []any{[]any{1.0, 2.0}, []any{1.0, "a"}},
+		},
+		{
+			description: "it should move definitions to $defs and follow refs to them",
+			schema:      `{ "$schema": "http://json-schema.org/draft-06/schema#", "definitions": { "price": { "type": "number", "minimum": 0 } }, "properties": { "ListPrice": { "$ref": "#/definitions/price" } } }`,
+			equivalent:  `{ "$defs": { "price": { "type": "number", "minimum": 0 } }, "properties": { "ListPrice": { "$ref": "#/$defs/price" } } }`,
+			inputs:      []any{map[string]any{"ListPrice": 5.0}, map[string]any{"ListPrice": -5.0}},
+		},
+		{
+			description: "it should ignore keywords next to $ref in draft-07",
+			schema:      `{ "$schema": "http://json-schema.org/draft-07/schema#", "definitions": { "price": { "type": "number" } }, "properties": { "ListPrice": { "$ref": "#/definitions/price", "maximum": 10 } } }`,
+			equivalent:  `{ "$defs": { "price": { "type": "number" } }, "properties": { "ListPrice": { "$ref": "#/$defs/price" } } }`,
+			inputs:      []any{map[string]any{"ListPrice": 50.0}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		s, err := Load(strings.NewReader(testCase.schema))
+		if err != nil {
+			t.Fatalf("%s: failed to load schema: %s", testCase.description, err)
+		}
+		equivalent, err := Load(strings.NewReader(testCase.equivalent))
+		if err != nil {
+			t.Fatalf("%s: failed to load equivalent schema: %s", testCase.description, err)
+		}
+		for _, input := range testCase.inputs {
+			out, err := s.Eval(input, false)
+			expectedOut, expectedErr := equivalent.Eval(input, false)
+			if (err == nil) != (expectedErr == nil) || !reflect.DeepEqual(out, expectedOut) {
+				t.Fatalf("%s: input <%v> evaluated to <%v> <%v>, expected <%v> <%v>", testCase.description, input, out, err, expectedOut, expectedErr)
+			}
+		}
+	}
+}
+
+func TestLoadUnknownDialect(t *testing.T) {
+	_, err := Load(strings.NewReader(`{ "$schema": "http://json-schema.org/draft-03/schema#" }`))
+	if !errors.Is(err, ErrDialect) {
+		t.Fatalf("expected error <%s>, got <%v>", ErrDialect, err)
+	}
+}
+
+func TestDialectOf(t *testing.T) {
+	testCases := []struct {
+		description string
+		uri         string
+		expected    string
+	}{
+		{description: "it should default to 2020-12", uri: "", expected: Draft202012},
+		{description: "it should ignore the empty fragment", uri: "http://json-schema.org/draft-07/schema#", expected: Draft07},
+		{description: "it should ignore the scheme", uri: "https://json-schema.org/draft-04/schema", expected: Draft04},
+	}
+
+	for _, testCase := range testCases {
+		draft, err := DialectOf(testCase.uri)
+		if err != nil {
+			t.Fatalf("%s: unexpected error <%s>", testCase.description, err)
+		}
+		if draft != testCase.expected {
+			t.Fatalf("%s: draft <%s> does not match expected <%s>", testCase.description, draft, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// key folding rules a schema can list in `x-key-folding`, applied when
+// matching keys to properties and their aliases
+const (
+	// FoldCase matches keys regardless of case, LISTPRICE matches ListPrice
+	FoldCase = "case"
+	// FoldSeparators ignores _, -, . and spaces, list_price matches listprice
+	FoldSeparators = "separators"
+)
+
+// policies for listing keys matched by no property, set in `x-unmapped`
+const (
+	// UnmappedAccept keeps the key:value as is
+	UnmappedAccept = "accept"
+	// UnmappedDrop removes the key:value from the listing
+	UnmappedDrop = "drop"
+	// UnmappedQuarantine moves the key:value to the extras of the listing
+	UnmappedQuarantine = "quarantine"
+	// UnmappedReject removes the key:value and fails the whole listing
+	UnmappedReject = "reject"
+)
+
+// WithUnmapped returns a shallow copy of s using policy for unmapped keys
+func (s *Schema) WithUnmapped(policy string) *Schema {
+	c := *s
+	c.Unmapped = policy
+	return &c
+}
+
+// Lookup returns the property of s matching key along with its canonical
+// name. a key matches a property by its name or one of its `x-aliases`,
+// compared after applying the `x-key-folding` rules of s
+func (s *Schema) Lookup(key string) (string, *Schema, bool) {
+	if s == nil || s.Properties == nil {
+		return key, nil, false
+	}
+	if ps, ok := (*s.Properties)[key]; ok {
+		return key, ps, true
+	}
+
+	folded := s.foldKey(key)
+	// compiled schemas have an index, otherwise every name is compared
+	if s.keys != nil {
+		if name, ok := s.keys[folded]; ok {
+			return name, (*s.Properties)[name], true
+		}
+		return key, nil, false
+	}
+	for _, name := range sortedKeys(*s.Properties) {
+		ps := (*s.Properties)[name]
+		if s.foldKey(name) == folded {
+			return name, ps, true
+		}
+		if ps == nil {
+			continue
+		}
+		for _, alias := range ps.Aliases {
+			if s.foldKey(alias) == folded {
+				return name, ps, true
+			}
+		}
+	}
+	return key, nil, false
+}
+
+// foldKey applies the key folding rules of s to key
+func (s *Schema) foldKey(key string) string {
+	for _, rule := range s.KeyFolding {
+		switch rule {
+		case FoldCase:
+			key = strings.ToLower(key)
+		case FoldSeparators:
+			key = strings.Map(func(r rune) rune {
+				if r == '_' || r == '-' || r == '.' || r == ' ' {
+					return -1
+				}
+				return r
+			}, key)
+		}
+	}
+	return key
+}
+
+// indexKeys builds the index Lookup uses, failing when two properties
+// can be matched by the same key
+func (s *Schema) indexKeys() error {
+	if s.Properties == nil {
+		return nil
+	}
+	index := map[string]string{}
+	add := func(key, name string) error {
+		folded := s.foldKey(key)
+		if other, ok := index[folded]; ok && other != name {
+			return fmt.Errorf("key <%s> of property <%s> matches property <%s> too", key, name, other)
+		}
+		index[folded] = name
+		return nil
+	}
+
+	names := make([]string, 0, len(*s.Properties))
+	for name := range *s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := add(name, name); err != nil {
+			return err
+		}
+	}
+	for _, name := range names {
+		if ps := (*s.Properties)[name]; ps != nil {
+			for _, alias := range ps.Aliases {
+				if err := add(alias, name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	s.keys = index
+	return nil
+}
+
+// patterns caches compiled patternProperties expressions by source, as
+// schemas are shared and usually unmarshaled rather than compiled
+var patterns sync.Map
+
+// pattern returns the compiled regular expression of a patternProperties key
+func pattern(expr string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid patternProperties <%s>: %w", expr, err)
+	}
+	patterns.Store(expr, re)
+	return re, nil
+}
+
+// Schemas returns the canonical name of key and the schemas its value
+// must match: the property matched by Lookup and every patternProperties
+// entry matching key. ok is false when neither matches
+func (s *Schema) Schemas(key string) (string, []*Schema, bool) {
+	if s == nil {
+		return key, nil, false
+	}
+	schemas := []*Schema{}
+	name, ps, ok := s.Lookup(key)
+	if ps != nil {
+		schemas = append(schemas, ps)
+	}
+	for _, expr := range sortedKeys(s.PatternProperties) {
+		re, err := pattern(expr)
+		// invalid expressions are reported by Compile, they match nothing
+		if err != nil || !re.MatchString(key) {
+			continue
+		}
+		ok = true
+		if pp := s.PatternProperties[expr]; pp != nil {
+			schemas = append(schemas, pp)
+		}
+	}
+	return name, schemas, ok
+}
+
+// EvalName evaluates key against the propertyNames schema of s
+func (s *Schema) EvalName(key string) error {
+	if s == nil || s.PropertyNames == nil {
+		return nil
+	}
+	// names are never coerced, and warnings are not worth dropping a key
+	_, err := s.PropertyNames.Eval(key, false)
+	if err != nil && !IsWarning(err) {
+		return fmt.Errorf("%w <%s>: %w", ErrPropertyName, key, err)
+	}
+	return nil
+}
+
+// EvalAll evaluates v against every one of schemas in turn, each one
+// receiving the possibly coerced value of the one before
+func EvalAll(schemas []*Schema, v any, opts EvalOptions) (any, error) {
+	warns := []error{}
+	for _, s := range schemas {
+		var err error
+		v, err = s.EvalWith(v, opts)
+		if IsWarning(err) {
+			warns = append(warns, err)
+		} else if err != nil {
+			return v, err
+		}
+	}
+	if len(warns) > 0 {
+		return v, &Warning{Err: errors.Join(warns...)}
+	}
+	return v, nil
+}
+
+// constrainsKeys reports whether s has keywords applying to the keys of
+// an object
+func (s *Schema) constrainsKeys() bool {
+	return s.Properties != nil || s.AdditionalProperties != nil || s.PatternProperties != nil || s.PropertyNames != nil
+}
@@ -0,0 +1,159 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	s, err := Load(strings.NewReader(`{
+		"x-key-folding": ["case", "separators"],
+		"properties": {
+			"ListPrice": { "type": "number", "x-aliases": ["L_AskingPrice"] },
+			"Remarks": { "type": "string" }
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+
+	testCases := []struct {
+		description string
+		key         string
+		expected    string
+		expectedOk  bool
+	}{
+		{description: "it should match the property name", key: "ListPrice", expected: "ListPrice", expectedOk: true},
+		{description: "it should fold case", key: "LISTPRICE", expected: "ListPrice", expectedOk: true},
+		{description: "it should fold separators", key: "list_price", expected: "ListPrice", expectedOk: true},
+		{description: "it should match aliases", key: "l-asking-price", expected: "ListPrice", expectedOk: true},
+		{description: "it should not match unknown keys", key: "Price", expected: "Price", expectedOk: false},
+	}
+
+	for _, testCase := range testCases {
+		name, _, ok := s.Lookup(testCase.key)
+		if name != testCase.expected || ok != testCase.expectedOk {
+			t.Fatalf("%s: lookup of <%s> returned <%s, %v>, expected <%s, %v>", testCase.description, testCase.key, name, ok, testCase.expected, testCase.expectedOk)
+		}
+	}
+}
+
+func TestIndexKeysConflict(t *testing.T) {
+	_, err := Load(strings.NewReader(`{
+		"x-key-folding": ["case"],
+		"properties": {
+			"ListPrice": { "type": "number" },
+			"Price": { "type": "number", "x-aliases": ["LISTPRICE"] }
+		}
+	}`))
+	if err == nil || !strings.Contains(err.Error(), "key <LISTPRICE> of property <Price> matches property <ListPrice> too") {
+		t.Fatalf("expected a conflict error, got <%v>", err)
+	}
+}
+
+func TestEvalAliases(t *testing.T) {
+	s, err := Load(strings.NewReader(`{
+		"type": "object",
+		"x-key-folding": ["case"],
+		"properties": {
+			"Area": { "type": "number", "x-aliases": ["SqFt"] }
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+
+	out, err := s.Eval(map[string]any{"sqft": 1200.0}, false)
+	if err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	if !reflect.DeepEqual(out, map[string]any{"Area": 1200.0}) {
+		t.Fatalf("expected aliased key to be renamed, got <%v>", out)
+	}
+
+	_, err = s.Eval(map[string]any{"SqFt": 1200.0, "area": 1300.0}, false)
+	if !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected a duplicate key error, got <%v>", err)
+	}
+}
+
+func TestEvalPatternProperties(t *testing.T) {
+	s, err := Load(strings.NewReader(`{
+		"type": "object",
+		"properties": { "Room1Level": { "enum": ["Main", "Upper", "Lower"] } },
+		"patternProperties": {
+			"^Room\\d+Level$": { "type": "string" },
+			"^Custom_": { "type": "number" }
+		},
+		"additionalProperties": { "type": "boolean" }
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+
+	testCases := []struct {
+		description string
+		input       map[string]any
+		expected    any
+		expectedErr string
+	}{
+		{
+			description: "it should apply every matching pattern along with the property",
+			input:       map[string]any{"Room1Level": "Main", "Room2Level": "Attic", "Custom_Dock": "1"},
+			expected:    map[string]any{"Room1Level": "Main", "Room2Level": "Attic", "Custom_Dock": 1},
+		},
+		{
+			description: "it should reject values failing a matching pattern",
+			input:       map[string]any{"Room2Level": 2.0},
+			expectedErr: "key <Room2Level>",
+		},
+		{
+			description: "it should reject values failing the property when the pattern passes",
+			input:       map[string]any{"Room1Level": "Attic"},
+			expectedErr: "key <Room1Level>",
+		},
+		{
+			description: "it should fall back to additionalProperties for unmatched keys",
+			input:       map[string]any{"Pool": "yes"},
+			expectedErr: "key <Pool>",
+		},
+	}
+
+	for _, testCase := range testCases {
+		out, err := s.Eval(testCase.input, true)
+		if testCase.expectedErr == "" && err != nil {
+			t.Fatalf("%s: unexpected error <%s>", testCase.description, err)
+		}
+		if testCase.expectedErr != "" {
+			if err == nil || !strings.Contains(err.Error(), testCase.expectedErr) {
+				t.Fatalf("%s: expected error containing <%s>, got <%v>", testCase.description, testCase.expectedErr, err)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(out, testCase.expected) {
+			t.Fatalf("%s: output <%v> does not match expected <%v>", testCase.description, out, testCase.expected)
+		}
+	}
+}
+
+func TestEvalName(t *testing.T) {
+	s, err := Load(strings.NewReader(`{ "propertyNames": { "enum": ["ListPrice", "Remarks"] } }`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	if err := s.EvalName("Remarks"); err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	if err := s.EvalName("Remark"); !errors.Is(err, ErrPropertyName) {
+		t.Fatalf("expected a property name error, got <%v>", err)
+	}
+}
+
+func TestLoadInvalidPattern(t *testing.T) {
+	_, err := Load(strings.NewReader(`{ "patternProperties": { "^Room(": { "type": "string" } } }`))
+	if err == nil || !strings.Contains(err.Error(), "invalid patternProperties <^Room(>") {
+		t.Fatalf("expected an invalid pattern error, got <%v>", err)
+	}
+}
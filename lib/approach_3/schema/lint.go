@@ -0,0 +1,413 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Finding is a single authoring mistake found by Lint
+type Finding struct {
+	Pointer string `json:"pointer"` // json pointer to the offending location
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	pointer := f.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return fmt.Sprintf("%s: %s: %s", pointer, f.Rule, f.Message)
+}
+
+// lint rules
+const (
+	RuleUnknownKeyword        = "unknown-keyword"
+	RuleUnsupportedKeyword    = "unsupported-keyword"
+	RuleInvalidType           = "invalid-type"
+	RuleItemsOnNonArray       = "items-on-non-array"
+	RulePropertiesOnNonObject = "properties-on-non-object"
+	RuleUnreachableAnyOf      = "unreachable-anyof"
+	RuleContradictoryRange    = "contradictory-range"
+	RuleDuplicateEnum         = "duplicate-enum"
+	RuleInvalidSeverity       = "invalid-severity"
+	RuleInvalidKeywordFormat  = "invalid-keyword"
+	RuleInvalidPattern        = "invalid-pattern"
+	RuleUnknownTransform      = "unknown-transform"
+	RuleUnknownUnit           = "unknown-unit"
+	RuleUnknownDialect        = "unknown-dialect"
+)
+
+// keywords accepted by Load, the propertyNames enum of the meta-schema,
+// mapped to whether Schema supports them. keywords the meta-schema does
+// not describe in properties load but have no effect
+var keywords = sync.OnceValue(func() map[string]bool {
+	meta := MetaSchema()
+	kws := make(map[string]bool, len(meta.PropertyNames.Enum))
+	for _, v := range meta.PropertyNames.Enum {
+		k, _ := v.(string)
+		_, supported := (*meta.Properties)[k]
+		kws[k] = supported
+	}
+	return kws
+})
+
+var typeNames = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"integer": true,
+	"number":  true,
+	"string":  true,
+	"array":   true,
+	"object":  true,
+}
+
+// Lint checks a raw schema document for mistakes json.Unmarshal into
+// Schema would not catch, such as misspelled keywords or unknown types.
+// documents of older drafts are normalized first like Load does, so
+// pointers are to the normalized document
+func Lint(data []byte) ([]Finding, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+	findings := []Finding{}
+	doc, _, err := normalize(doc)
+	if err != nil {
+		findings = append(findings, Finding{Pointer: "/$schema", Rule: RuleUnknownDialect, Message: err.Error()})
+	}
+	lintNode(doc, "", &findings)
+	return findings, nil
+}
+
+func lintNode(node any, pointer string, findings *[]Finding) {
+	add := func(ptr, rule, format string, args ...any) {
+		*findings = append(*findings, Finding{Pointer: ptr, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	obj, ok := node.(map[string]any)
+	if !ok {
+		add(pointer, RuleInvalidKeywordFormat, "schema must be an object, got <%s>", GetDataType(node))
+		return
+	}
+
+	for _, k := range sortedKeys(obj) {
+		if supported, ok := keywords()[k]; ok {
+			if !supported {
+				add(pointer+"/"+escapePointer(k), RuleUnsupportedKeyword, "keyword <%s> is not supported and has no effect", k)
+			}
+			continue
+		}
+		if suggestion := closestKeyword(k); suggestion != "" {
+			add(pointer+"/"+escapePointer(k), RuleUnknownKeyword, "unknown keyword <%s>, did you mean <%s>?", k, suggestion)
+		} else {
+			add(pointer+"/"+escapePointer(k), RuleUnknownKeyword, "unknown keyword <%s>", k)
+		}
+	}
+
+	types, typed := lintType(obj, pointer, add)
+
+	for _, k := range []string{"items", "prefixItems"} {
+		if _, ok := obj[k]; ok && typed && !types["array"] {
+			add(pointer+"/"+k, RuleItemsOnNonArray, "%s has no effect, type <%v> does not allow arrays", k, obj["type"])
+		}
+	}
+	for _, k := range []string{"properties", "required", "additionalProperties", "patternProperties", "propertyNames"} {
+		if _, ok := obj[k]; ok && typed && !types["object"] {
+			add(pointer+"/"+k, RulePropertiesOnNonObject, "%s has no effect, type <%v> does not allow objects", k, obj["type"])
+		}
+	}
+
+	// an inclusive pair only contradicts when the lower bound is greater,
+	// any pair with an exclusive bound already does when they are equal
+	for _, bounds := range [][2]string{{"minimum", "maximum"}, {"exclusiveMinimum", "maximum"}, {"minimum", "exclusiveMaximum"}, {"exclusiveMinimum", "exclusiveMaximum"}} {
+		min, hasMin := obj[bounds[0]].(float64)
+		max, hasMax := obj[bounds[1]].(float64)
+		if !hasMin || !hasMax {
+			continue
+		}
+		if min > max {
+			add(pointer, RuleContradictoryRange, "%s <%v> is greater than %s <%v>, no value can match", bounds[0], min, bounds[1], max)
+		} else if min == max && bounds != [2]string{"minimum", "maximum"} {
+			add(pointer, RuleContradictoryRange, "%s <%v> is equal to %s <%v>, no value can match", bounds[0], min, bounds[1], max)
+		}
+	}
+	for _, k := range []string{"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum"} {
+		if v, ok := obj[k]; ok {
+			if _, isNum := v.(float64); !isNum {
+				add(pointer+"/"+k, RuleInvalidKeywordFormat, "%s must be a number, got <%s>", k, GetDataType(v))
+			}
+		}
+	}
+
+	for _, kw := range [][2]string{{"title", "string"}, {"description", "string"}, {"examples", "array"}, {"deprecated", "boolean"}, {"readOnly", "boolean"}} {
+		if v, ok := obj[kw[0]]; ok && GetDataType(v) != kw[1] {
+			add(pointer+"/"+kw[0], RuleInvalidKeywordFormat, "%s must be a %s, got <%s>", kw[0], kw[1], GetDataType(v))
+		}
+	}
+
+	if v, ok := obj["enum"]; ok {
+		enum, isArr := v.([]any)
+		if !isArr {
+			add(pointer+"/enum", RuleInvalidKeywordFormat, "enum must be an array, got <%s>", GetDataType(v))
+		}
+		for i := range enum {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(enum[i], enum[j]) {
+					add(fmt.Sprintf("%s/enum/%d", pointer, i), RuleDuplicateEnum, "enum value <%v> duplicates index %d", enum[i], j)
+					break
+				}
+			}
+		}
+	}
+
+	if v, ok := obj["x-severity"]; ok && v != SeverityError && v != SeverityWarning {
+		add(pointer+"/x-severity", RuleInvalidSeverity, "severity <%v> is not one of <%s, %s>", v, SeverityError, SeverityWarning)
+	}
+
+	if v, ok := obj["x-unmapped"]; ok && v != UnmappedAccept && v != UnmappedDrop && v != UnmappedQuarantine && v != UnmappedReject {
+		add(pointer+"/x-unmapped", RuleInvalidKeywordFormat, "unmapped policy <%v> is not one of <%s, %s, %s, %s>", v, UnmappedAccept, UnmappedDrop, UnmappedQuarantine, UnmappedReject)
+	}
+
+	if v, ok := obj["x-transform"].([]any); ok {
+		for i, entry := range v {
+			if name, isStr := entry.(string); isStr {
+				name, _ = parseTransform(name)
+				if _, registered := lookupTransform(name); !registered {
+					add(fmt.Sprintf("%s/x-transform/%d", pointer, i), RuleUnknownTransform, "transform <%s> is not registered", name)
+				}
+			}
+		}
+	}
+
+	for _, k := range []string{"x-unit", "x-source-unit"} {
+		if name, ok := obj[k].(string); ok {
+			if _, known := lookupUnit(name); !known {
+				add(pointer+"/"+k, RuleUnknownUnit, "unit <%s> is not registered", name)
+			}
+		}
+	}
+
+	if v, ok := obj["properties"]; ok {
+		props, isObj := v.(map[string]any)
+		if !isObj {
+			add(pointer+"/properties", RuleInvalidKeywordFormat, "properties must be an object, got <%s>", GetDataType(v))
+		}
+		for _, k := range sortedKeys(props) {
+			lintNode(props[k], pointer+"/properties/"+escapePointer(k), findings)
+		}
+	}
+
+	if v, ok := obj["items"]; ok {
+		lintNode(v, pointer+"/items", findings)
+	}
+
+	if v, ok := obj["prefixItems"]; ok {
+		prefix, isArr := v.([]any)
+		if !isArr {
+			add(pointer+"/prefixItems", RuleInvalidKeywordFormat, "prefixItems must be an array, got <%s>", GetDataType(v))
+		}
+		for i, item := range prefix {
+			lintNode(item, fmt.Sprintf("%s/prefixItems/%d", pointer, i), findings)
+		}
+	}
+
+	if v, ok := obj["additionalProperties"]; ok {
+		lintNode(v, pointer+"/additionalProperties", findings)
+	}
+
+	if v, ok := obj["patternProperties"]; ok {
+		patternProps, isObj := v.(map[string]any)
+		if !isObj {
+			add(pointer+"/patternProperties", RuleInvalidKeywordFormat, "patternProperties must be an object, got <%s>", GetDataType(v))
+		}
+		for _, k := range sortedKeys(patternProps) {
+			if _, err := pattern(k); err != nil {
+				add(pointer+"/patternProperties/"+escapePointer(k), RuleInvalidPattern, "%s", err)
+			}
+			lintNode(patternProps[k], pointer+"/patternProperties/"+escapePointer(k), findings)
+		}
+	}
+
+	if v, ok := obj["propertyNames"]; ok {
+		lintNode(v, pointer+"/propertyNames", findings)
+	}
+
+	if v, ok := obj["$defs"]; ok {
+		defs, isObj := v.(map[string]any)
+		if !isObj {
+			add(pointer+"/$defs", RuleInvalidKeywordFormat, "$defs must be an object, got <%s>", GetDataType(v))
+		}
+		for _, k := range sortedKeys(defs) {
+			lintNode(defs[k], pointer+"/$defs/"+escapePointer(k), findings)
+		}
+	}
+
+	if v, ok := obj["anyOf"]; ok {
+		branches, isArr := v.([]any)
+		if !isArr {
+			add(pointer+"/anyOf", RuleInvalidKeywordFormat, "anyOf must be an array, got <%s>", GetDataType(v))
+		}
+		for i, branch := range branches {
+			lintNode(branch, fmt.Sprintf("%s/anyOf/%d", pointer, i), findings)
+			for j := 0; j < i; j++ {
+				if subsumes(branches[j], branch) {
+					add(fmt.Sprintf("%s/anyOf/%d", pointer, i), RuleUnreachableAnyOf, "branch is unreachable, branch %d accepts every value it does", j)
+					break
+				}
+			}
+		}
+	}
+}
+
+// lintType checks the type keyword, returning the set of valid type names
+// and whether the node is typed at all
+func lintType(obj map[string]any, pointer string, add func(ptr, rule, format string, args ...any)) (map[string]bool, bool) {
+	v, ok := obj["type"]
+	if !ok {
+		return nil, false
+	}
+
+	names := []any{v}
+	if arr, isArr := v.([]any); isArr {
+		names = arr
+	}
+
+	types := map[string]bool{}
+	for i, n := range names {
+		ptr := pointer + "/type"
+		if _, isArr := v.([]any); isArr {
+			ptr = fmt.Sprintf("%s/type/%d", pointer, i)
+		}
+		name, isStr := n.(string)
+		if !isStr {
+			add(ptr, RuleInvalidType, "type must be a string or an array of strings, got <%s>", GetDataType(n))
+			continue
+		}
+		if !typeNames[name] {
+			add(ptr, RuleInvalidType, "unknown type <%s>, expected one of <%s>", name, strings.Join(sortedKeys(typeNames), ", "))
+			continue
+		}
+		types[name] = true
+	}
+	// integers are numbers, so a number type allows them too
+	if types["number"] {
+		types["integer"] = true
+	}
+	return types, true
+}
+
+// subsumes conservatively reports whether schema a accepts every value
+// schema b accepts: a must either be identical to b or only constrain
+// the type, with b's types all allowed by a
+func subsumes(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	aObj, ok := a.(map[string]any)
+	if !ok {
+		return false
+	}
+	for k := range aObj {
+		if k != "type" && k != "x-severity" {
+			return false
+		}
+	}
+	aType, constrained := aObj["type"]
+	if !constrained {
+		return true
+	}
+	bObj, ok := b.(map[string]any)
+	if !ok {
+		return false
+	}
+	bType, ok := bObj["type"]
+	if !ok {
+		return false
+	}
+
+	allowed := map[string]bool{}
+	for _, t := range typeList(aType) {
+		allowed[t] = true
+		if t == "number" {
+			allowed["integer"] = true
+		}
+	}
+	for _, t := range typeList(bType) {
+		if !allowed[t] {
+			return false
+		}
+	}
+	return true
+}
+
+func typeList(v any) []string {
+	if s, ok := v.(string); ok {
+		return []string{s}
+	}
+	out := []string{}
+	if arr, ok := v.([]any); ok {
+		for _, t := range arr {
+			if s, ok := t.(string); ok {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// closestKeyword returns the known keyword within an edit distance of 2
+// of k, if any
+func closestKeyword(k string) string {
+	best, bestDist := "", 3
+	for _, kw := range sortedKeys(keywords()) {
+		if d := editDistance(strings.ToLower(k), strings.ToLower(kw)); d < bestDist {
+			best, bestDist = kw, d
+		}
+	}
+	return best
+}
+
+// editDistance is the optimal string alignment distance between a and b,
+// counting a swap of two adjacent characters, as in "tpye", as one edit
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func escapePointer(k string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(k, "~", "~0"), "/", "~1")
+}
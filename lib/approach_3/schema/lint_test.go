@@ -0,0 +1,134 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	testCases := []struct {
+		description string
+		schema      []byte
+		expected    []Finding
+	}{
+		{
+			description: "it should not report a valid schema",
+			schema: []byte(`{
+				"properties": {
+					"ListPrice": { "type": "number", "minimum": 0, "maximum": 50000000, "x-severity": "warning" },
+					"Appliances": { "type": "array", "items": { "type": "string" } },
+					"Status": { "enum": ["Active", "Closed"] }
+				}
+			}`),
+			expected: []Finding{},
+		},
+		{
+			description: "it should lint $defs and additionalProperties",
+			schema:      []byte(`{ "$defs": { "price": { "tpye": "number" } }, "additionalProperties": { "$ref": "#/$defs/price" } }`),
+			expected: []Finding{
+				{Pointer: "/$defs/price/tpye", Rule: RuleUnknownKeyword, Message: "unknown keyword <tpye>, did you mean <type>?"},
+			},
+		},
+		{
+			description: "it should report invalid patterns and lint patternProperties",
+			schema:      []byte(`{ "patternProperties": { "^Room(\\d+Level$": { "type": "string" }, "^Custom_": { "tpye": "string" } } }`),
+			expected: []Finding{
+				{Pointer: "/patternProperties/^Custom_/tpye", Rule: RuleUnknownKeyword, Message: "unknown keyword <tpye>, did you mean <type>?"},
+				{Pointer: "/patternProperties/^Room(\\d+Level$", Rule: RuleInvalidPattern, Message: "invalid patternProperties <^Room(\\d+Level$>: error parsing regexp: missing closing ): `^Room(\\d+Level$`"},
+			},
+		},
+		{
+			description: "it should lint older drafts once normalized",
+			schema:      []byte(`{ "$schema": "http://json-schema.org/draft-07/schema#", "definitions": { "price": { "tpye": "number" } }, "items": [ { "$ref": "#/definitions/price" } ] }`),
+			expected: []Finding{
+				{Pointer: "/$defs/price/tpye", Rule: RuleUnknownKeyword, Message: "unknown keyword <tpye>, did you mean <type>?"},
+			},
+		},
+		{
+			description: "it should check the format of annotations",
+			schema:      []byte(`{ "properties": { "LotSizeAcres": { "title": "Lot size", "description": "in acres", "examples": [ 1 ], "deprecated": "yes" } } }`),
+			expected: []Finding{
+				{Pointer: "/properties/LotSizeAcres/deprecated", Rule: RuleInvalidKeywordFormat, Message: "deprecated must be a boolean, got <string>"},
+			},
+		},
+		{
+			description: "it should report unknown dialects",
+			schema:      []byte(`{ "$schema": "http://json-schema.org/draft-03/schema#" }`),
+			expected: []Finding{
+				{Pointer: "/$schema", Rule: RuleUnknownDialect, Message: "unsupported $schema dialect <http://json-schema.org/draft-03/schema#>"},
+			},
+		},
+		{
+			description: "it should report misspelled keywords with a suggestion",
+			schema:      []byte(`{ "propertis": { "ListPrice": { "tpye": "number" } } }`),
+			expected: []Finding{
+				{Pointer: "/propertis", Rule: RuleUnknownKeyword, Message: "unknown keyword <propertis>, did you mean <properties>?"},
+			},
+		},
+		{
+			description: "it should report unsupported keywords but not annotations",
+			schema:      []byte(`{ "$id": "https://example.com/listing", "properties": { "Remarks": { "type": "string", "default": "", "maxLength": 1024 } } }`),
+			expected: []Finding{
+				{Pointer: "/properties/Remarks/maxLength", Rule: RuleUnsupportedKeyword, Message: "keyword <maxLength> is not supported and has no effect"},
+			},
+		},
+		{
+			description: "it should report misspelled keywords of nested schemas",
+			schema:      []byte(`{ "properties": { "ListPrice": { "tpye": "number", "x-foo": true } } }`),
+			expected: []Finding{
+				{Pointer: "/properties/ListPrice/tpye", Rule: RuleUnknownKeyword, Message: "unknown keyword <tpye>, did you mean <type>?"},
+				{Pointer: "/properties/ListPrice/x-foo", Rule: RuleUnknownKeyword, Message: "unknown keyword <x-foo>"},
+			},
+		},
+		{
+			description: "it should report unknown type names",
+			schema:      []byte(`{ "properties": { "ListPrice": { "type": ["int", "null"] } } }`),
+			expected: []Finding{
+				{Pointer: "/properties/ListPrice/type/0", Rule: RuleInvalidType, Message: "unknown type <int>, expected one of <array, boolean, integer, null, number, object, string>"},
+			},
+		},
+		{
+			description: "it should report items and properties on the wrong types",
+			schema:      []byte(`{ "properties": { "Geo": { "type": "string", "items": {}, "properties": {} } } }`),
+			expected: []Finding{
+				{Pointer: "/properties/Geo/items", Rule: RuleItemsOnNonArray, Message: "items has no effect, type <string> does not allow arrays"},
+				{Pointer: "/properties/Geo/properties", Rule: RulePropertiesOnNonObject, Message: "properties has no effect, type <string> does not allow objects"},
+			},
+		},
+		{
+			description: "it should report contradictory ranges and duplicate enum values",
+			schema:      []byte(`{ "properties": { "Beds": { "minimum": 10, "maximum": 1, "enum": [1, 2, 1] } } }`),
+			expected: []Finding{
+				{Pointer: "/properties/Beds", Rule: RuleContradictoryRange, Message: "minimum <10> is greater than maximum <1>, no value can match"},
+				{Pointer: "/properties/Beds/enum/2", Rule: RuleDuplicateEnum, Message: "enum value <1> duplicates index 0"},
+			},
+		},
+		{
+			description: "it should report contradictory ranges with exclusive bounds",
+			schema:      []byte(`{ "properties": { "Beds": { "exclusiveMinimum": 5, "maximum": 5 }, "Baths": { "minimum": 5, "exclusiveMaximum": 4 }, "Rooms": { "exclusiveMinimum": 3, "exclusiveMaximum": 3 }, "Stories": { "minimum": 3, "maximum": 3 } } }`),
+			expected: []Finding{
+				{Pointer: "/properties/Baths", Rule: RuleContradictoryRange, Message: "minimum <5> is greater than exclusiveMaximum <4>, no value can match"},
+				{Pointer: "/properties/Beds", Rule: RuleContradictoryRange, Message: "exclusiveMinimum <5> is equal to maximum <5>, no value can match"},
+				{Pointer: "/properties/Rooms", Rule: RuleContradictoryRange, Message: "exclusiveMinimum <3> is equal to exclusiveMaximum <3>, no value can match"},
+			},
+		},
+		{
+			description: "it should report anyOf branches shadowed by an earlier branch",
+			schema:      []byte(`{ "anyOf": [{ "type": ["number", "string"] }, { "type": "string", "enum": ["a"] }, { "type": "boolean" }, {}, { "type": "null" }] }`),
+			expected: []Finding{
+				{Pointer: "/anyOf/1", Rule: RuleUnreachableAnyOf, Message: "branch is unreachable, branch 0 accepts every value it does"},
+				{Pointer: "/anyOf/4", Rule: RuleUnreachableAnyOf, Message: "branch is unreachable, branch 3 accepts every value it does"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		findings, err := Lint(testCase.schema)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", testCase.description, err)
+		}
+		if !reflect.DeepEqual(findings, testCase.expected) {
+			t.Fatalf("%s: findings <%+v> do not match expected <%+v>", testCase.description, findings, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package schema
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// metaSchemaDoc describes the subset of json schema supported by Schema.
+// other keywords of json schema are accepted without effect, anything
+// else is rejected
+//
+//go:embed metaschema.json
+var metaSchemaDoc []byte
+
+var metaSchema = sync.OnceValues(func() (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(metaSchemaDoc, &s); err != nil {
+		return nil, err
+	}
+	if err := s.Compile(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+})
+
+// MetaSchema returns the compiled meta-schema schema documents are
+// validated against by Load
+func MetaSchema() *Schema {
+	s, err := metaSchema()
+	if err != nil {
+		// the meta-schema is embedded, failing to load it is a bug
+		panic(fmt.Sprintf("failed to load embedded meta-schema: %s", err))
+	}
+	return s
+}
+
+// Load reads a schema document from r, normalizes the keywords of older
+// drafts according to its $schema, validates it against the meta-schema
+// and returns it compiled, ready to Eval
+func Load(r io.Reader) (*Schema, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("schema is not valid json: %w", err)
+	}
+	doc, changed, err := normalize(doc)
+	if err != nil {
+		return nil, err
+	}
+	// older drafts are unmarshaled from their normalized document
+	if changed {
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf("failed to marshal normalized schema: %w", err)
+		}
+	}
+	if _, err := MetaSchema().Eval(doc, false); err != nil {
+		return nil, fmt.Errorf("schema does not match the meta-schema: %w", err)
+	}
+
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+	if err := s.Compile(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Compile resolves every $ref of s, which must be the root of its
+// document, and indexes property keys and aliases. it has to be called
+// before evaluating a schema using $ref. it also checks the regular
+// expressions of patternProperties and that every transform and unit is
+// registered
+func (s *Schema) Compile() error {
+	errs := []error{}
+	nodes := []*Schema{}
+	s.walk(func(node *Schema) {
+		nodes = append(nodes, node)
+		if err := node.indexKeys(); err != nil {
+			errs = append(errs, err)
+		}
+		if err := node.checkUnits(); err != nil {
+			errs = append(errs, err)
+		}
+		if err := node.checkTransforms(); err != nil {
+			errs = append(errs, err)
+		}
+		for expr := range node.PatternProperties {
+			if _, err := pattern(expr); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if node.Ref == "" {
+			return
+		}
+		target, err := s.resolvePointer(node.Ref)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		node.ref = target
+	})
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// a $ref leading back to a schema that applies to the same value,
+	// directly or through anyOf, would recurse forever
+	done := map[*Schema]bool{}
+	for _, node := range nodes {
+		if err := checkCycle(node, []*Schema{}, done); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCycle follows the subschemas applying to the same value as node,
+// its $ref and anyOf branches, and fails if one of them is on path.
+// nodes in done are known not to lead to a cycle
+func checkCycle(node *Schema, path []*Schema, done map[*Schema]bool) error {
+	if node == nil || done[node] {
+		return nil
+	}
+	for i, p := range path {
+		if p != node {
+			continue
+		}
+		// a cycle of subschemas always goes through a $ref
+		for _, c := range path[i:] {
+			if c.Ref != "" {
+				return fmt.Errorf("$ref <%s> is circular", c.Ref)
+			}
+		}
+		return nil
+	}
+	path = append(path, node)
+	next := append([]*Schema{node.ref}, node.AnyOf...)
+	for _, n := range next {
+		if err := checkCycle(n, path, done); err != nil {
+			return err
+		}
+	}
+	done[node] = true
+	return nil
+}
+
+// walk calls fn on s and every subschema of s
+func (s *Schema) walk(fn func(*Schema)) {
+	if s == nil {
+		return
+	}
+	fn(s)
+	if s.Properties != nil {
+		for _, p := range *s.Properties {
+			p.walk(fn)
+		}
+	}
+	s.Items.walk(fn)
+	for _, b := range s.AnyOf {
+		b.walk(fn)
+	}
+	for _, p := range s.PrefixItems {
+		p.walk(fn)
+	}
+	for _, d := range s.Defs {
+		d.walk(fn)
+	}
+	s.AdditionalProperties.walk(fn)
+	for _, p := range s.PatternProperties {
+		p.walk(fn)
+	}
+	s.PropertyNames.walk(fn)
+}
+
+// resolvePointer resolves a "#" relative json pointer into s
+func (s *Schema) resolvePointer(ref string) (*Schema, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref <%s>, only refs within the document starting with # are supported", ref)
+	}
+	pointer := strings.TrimPrefix(ref, "#")
+	if pointer == "" {
+		return s, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid $ref <%s>, expected a json pointer", ref)
+	}
+
+	segments := strings.Split(pointer[1:], "/")
+	cur := s
+	for i := 0; i < len(segments); i++ {
+		if cur == nil {
+			break
+		}
+		seg := unescapePointer(segments[i])
+		// keywords holding a map or an array of schemas are followed by
+		// the key or index of the subschema
+		next := ""
+		if seg == "properties" || seg == "patternProperties" || seg == "$defs" || seg == "anyOf" || seg == "prefixItems" {
+			i++
+			if i >= len(segments) {
+				return nil, fmt.Errorf("invalid $ref <%s>, <%s> must be followed by a key", ref, seg)
+			}
+			next = unescapePointer(segments[i])
+		}
+
+		switch seg {
+		case "properties":
+			if cur.Properties == nil {
+				return nil, fmt.Errorf("invalid $ref <%s>, no properties at <%s>", ref, seg)
+			}
+			cur = (*cur.Properties)[next]
+		case "patternProperties":
+			cur = cur.PatternProperties[next]
+		case "$defs":
+			cur = cur.Defs[next]
+		case "anyOf":
+			idx, err := strconv.Atoi(next)
+			if err != nil || idx < 0 || idx >= len(cur.AnyOf) {
+				return nil, fmt.Errorf("invalid $ref <%s>, no anyOf branch <%s>", ref, next)
+			}
+			cur = cur.AnyOf[idx]
+		case "prefixItems":
+			idx, err := strconv.Atoi(next)
+			if err != nil || idx < 0 || idx >= len(cur.PrefixItems) {
+				return nil, fmt.Errorf("invalid $ref <%s>, no prefixItems entry <%s>", ref, next)
+			}
+			cur = cur.PrefixItems[idx]
+		case "items":
+			cur = cur.Items
+		case "additionalProperties":
+			cur = cur.AdditionalProperties
+		case "propertyNames":
+			cur = cur.PropertyNames
+		default:
+			return nil, fmt.Errorf("invalid $ref <%s>, unsupported segment <%s>", ref, seg)
+		}
+	}
+	if cur == nil {
+		return nil, fmt.Errorf("invalid $ref <%s>, it points to no schema", ref)
+	}
+	return cur, nil
+}
+
+func unescapePointer(seg string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(seg, "~1", "/"), "~0", "~")
+}
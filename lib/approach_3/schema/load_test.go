@@ -0,0 +1,125 @@
+package schema
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		description string
+		schema      string
+		expectedErr string
+	}{
+		{
+			description: "it should load a valid schema",
+			schema:      `{ "properties": { "ListPrice": { "type": ["number", "null"], "maximum": 50000000, "x-severity": "warning" } } }`,
+		},
+		{
+			description: "it should load a schema using $ref and $defs",
+			schema:      `{ "$defs": { "price": { "type": "number", "minimum": 0 } }, "properties": { "ListPrice": { "$ref": "#/$defs/price" } } }`,
+		},
+		{
+			description: "it should reject invalid json",
+			schema:      `{ "properties": `,
+			expectedErr: "schema is not valid json",
+		},
+		{
+			description: "it should reject unknown type names",
+			schema:      `{ "properties": { "ListPrice": { "type": "int" } } }`,
+			expectedErr: "the value <int> is not one of <[null boolean integer number string array object]>",
+		},
+		{
+			description: "it should reject keywords of the wrong type",
+			schema:      `{ "properties": { "ListPrice": { "maximum": "50000000" } } }`,
+			expectedErr: "key <maximum>",
+		},
+		{
+			description: "it should reject nested invalid schemas",
+			schema:      `{ "properties": { "Appliances": { "items": { "x-severity": "info" } } } }`,
+			expectedErr: "key <x-severity>",
+		},
+		{
+			description: "it should reject misspelled keywords",
+			schema:      `{ "properties": { "ListPrice": { "type": "number", "minimun": 0 } } }`,
+			expectedErr: "invalid property name <minimun>",
+		},
+		{
+			description: "it should load the annotation and unsupported keywords of older drafts",
+			schema:      `{ "$schema": "http://json-schema.org/draft-07/schema#", "$id": "https://example.com/listing", "$comment": "listing", "properties": { "Remarks": { "type": "string", "maxLength": 1024, "default": "", "format": "text" } } }`,
+		},
+		{
+			description: "it should reject an empty type array",
+			schema:      `{ "properties": { "ListPrice": { "type": [] } } }`,
+			expectedErr: "type field must list at least one type",
+		},
+		{
+			description: "it should reject unresolvable refs",
+			schema:      `{ "properties": { "ListPrice": { "$ref": "#/$defs/price" } } }`,
+			expectedErr: "invalid $ref <#/$defs/price>",
+		},
+		{
+			description: "it should reject circular refs",
+			schema:      `{ "$defs": { "a": { "$ref": "#/$defs/b" }, "b": { "$ref": "#/$defs/a" } } }`,
+			expectedErr: "is circular",
+		},
+		{
+			description: "it should reject refs applying the same schema to the same value through anyOf",
+			schema:      `{ "anyOf": [ { "$ref": "#" } ] }`,
+			expectedErr: "$ref <#> is circular",
+		},
+		{
+			description: "it should accept recursive refs that apply to a nested value",
+			schema:      `{ "type": "object", "properties": { "Rooms": { "type": "array", "items": { "$ref": "#" } } } }`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		_, err := Load(strings.NewReader(testCase.schema))
+		if testCase.expectedErr == "" && err != nil {
+			t.Fatalf("%s: unexpected error <%s>", testCase.description, err)
+		}
+		if testCase.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), testCase.expectedErr)) {
+			t.Fatalf("%s: expected error containing <%s>, got <%v>", testCase.description, testCase.expectedErr, err)
+		}
+	}
+}
+
+func TestLoadRepoSchema(t *testing.T) {
+	file, err := os.Open("../../../schema.json")
+	if err != nil {
+		t.Fatalf("failed to open schema: %s", err)
+	}
+	defer file.Close()
+	if _, err := Load(file); err != nil {
+		t.Fatalf("repository schema does not match the meta-schema: %s", err)
+	}
+}
+
+func TestRef(t *testing.T) {
+	s, err := Load(strings.NewReader(`{
+		"$defs": { "price": { "type": "number", "minimum": 0 } },
+		"properties": {
+			"ListPrice": { "$ref": "#/$defs/price" },
+			"Rooms": { "type": "array", "items": { "$ref": "#" } }
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+
+	if _, err := s.Eval(map[string]any{"ListPrice": 10.0}, false); err != nil {
+		t.Fatalf("expected referenced schema to accept value, got <%s>", err)
+	}
+	if _, err := s.Eval(map[string]any{"ListPrice": -1.0}, false); err == nil {
+		t.Fatalf("expected referenced schema to reject a negative price")
+	}
+	// the recursive ref applies the root schema to every room
+	if _, err := s.Eval(map[string]any{"Rooms": []any{map[string]any{"ListPrice": -1.0}}}, false); err == nil {
+		t.Fatalf("expected recursive ref to reject a nested negative price")
+	}
+	if _, err := (&Schema{Ref: "#"}).Eval(1.0, false); err == nil {
+		t.Fatalf("expected an uncompiled ref to fail")
+	}
+}
@@ -0,0 +1,53 @@
+package schema
+
+import "strings"
+
+// EvalOptions tune EvalWith
+type EvalOptions struct {
+	// Coerce stringified values into their schema type
+	Coerce bool
+	// Partial returns the valid keys of an object along its error rather
+	// than nil, as long as the error is tied to keys
+	Partial bool
+	// Siblings are the other keys of the object holding the value, by
+	// property name, read by keywords such as `x-unit-from`
+	Siblings map[string]any
+	// Path is the json pointer of the value, prefixed to audited paths
+	Path string
+	// Audit is called with every change made to the value other than
+	// coercion, such as a unit conversion
+	Audit func(e AuditEntry)
+	// Annotate turns on annotation collection, it is called with the
+	// annotations of every schema the value, or a part of it, is valid
+	// against
+	Annotate func(a Annotation)
+}
+
+// AuditEntry records a change made to a value during EvalWith
+type AuditEntry struct {
+	Path   string `json:"path"` // json pointer to the changed value
+	Action string `json:"action"`
+	From   any    `json:"from"`
+	To     any    `json:"to"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// audit actions
+const (
+	AuditUnit = "unit"
+)
+
+// child returns the options to evaluate the value under key, held by an
+// object with siblings or by an array when siblings is nil
+func (o EvalOptions) child(key string, siblings map[string]any) EvalOptions {
+	o.Path += "/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+	o.Siblings = siblings
+	return o
+}
+
+func (o EvalOptions) audit(e AuditEntry) {
+	if o.Audit != nil {
+		e.Path = o.Path
+		o.Audit(e)
+	}
+}
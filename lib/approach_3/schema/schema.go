@@ -4,12 +4,33 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"strconv"
 )
 
 type SchemaMap map[string]*Schema
 
+// sentinel errors wrapped by Eval so callers can classify failures
+// with errors.Is without parsing messages
+var (
+	ErrTypeMismatch  = errors.New("type mismatch")
+	ErrCoerce        = errors.New("failed to coerce value")
+	ErrInvalidItems  = errors.New("failed to validate all items in array")
+	ErrInvalidObject = errors.New("failed to validate object")
+	ErrRange         = errors.New("value out of range")
+	ErrEnum          = errors.New("value not in enum")
+	ErrRequired      = errors.New("missing required property")
+	ErrAnyOf         = errors.New("value does not match any schema in anyOf")
+	ErrDuplicateKey  = errors.New("duplicate key")
+	ErrPropertyName  = errors.New("invalid property name")
+	ErrUnmapped      = errors.New("no schema mapping")
+	ErrTransform     = errors.New("failed to transform value")
+	ErrUnit          = errors.New("failed to convert unit")
+	ErrDialect       = errors.New("unsupported $schema dialect")
+	ErrDeprecated    = errors.New("deprecated property")
+)
+
 type Type []string
 
 func (t *Type) UnmarshalJSON(data []byte) error {
@@ -21,6 +42,9 @@ func (t *Type) UnmarshalJSON(data []byte) error {
 
 	var multi []string
 	if err := json.Unmarshal(data, &multi); err == nil {
+		if len(multi) == 0 {
+			return errors.New("type field must list at least one type")
+		}
 		*t = Type(multi)
 		return nil
 	}
@@ -28,10 +52,85 @@ func (t *Type) UnmarshalJSON(data []byte) error {
 	return errors.New(fmt.Sprintf("type field not single string or array of strings, value <%v>", string(data)))
 }
 
+// MarshalJSON writes a single type as a string, the way it is usually written
+func (t Type) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
 type Schema struct {
 	Properties *map[string]*Schema `json:"properties,omitempty"`
 	Type       Type                `json:"type,omitempty"`
 	Items      *Schema             `json:"items,omitempty"`
+	Minimum    *float64            `json:"minimum,omitempty"`
+	Maximum    *float64            `json:"maximum,omitempty"`
+	Enum       []any               `json:"enum,omitempty"`
+	Required   []string            `json:"required,omitempty"`
+	AnyOf      []*Schema           `json:"anyOf,omitempty"`
+	Ref        string              `json:"$ref,omitempty"`
+	Defs       map[string]*Schema  `json:"$defs,omitempty"`
+	Severity   string              `json:"x-severity,omitempty"`
+
+	// ExclusiveMinimum and ExclusiveMaximum are bounds numbers must be
+	// strictly above and below
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+	// PrefixItems apply to the items of an array by position, Items to
+	// the items after them
+	PrefixItems []*Schema `json:"prefixItems,omitempty"`
+
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+	// PatternProperties apply to the values of every key matching their
+	// regular expression, in RE2 syntax
+	PatternProperties map[string]*Schema `json:"patternProperties,omitempty"`
+	// PropertyNames is evaluated against every key of an object
+	PropertyNames *Schema `json:"propertyNames,omitempty"`
+
+	// Aliases are other keys feeds send for this property
+	Aliases []string `json:"x-aliases,omitempty"`
+	// KeyFolding lists the rules, FoldCase and FoldSeparators, used to
+	// match keys to the properties of this schema
+	KeyFolding []string `json:"x-key-folding,omitempty"`
+	// Unmapped is the policy for top level keys without a property,
+	// one of the Unmapped constants. keys are accepted by default
+	Unmapped string `json:"x-unmapped,omitempty"`
+	// Transforms normalize the value, in order, before its assertions
+	// are checked. entries are registered transform names with optional
+	// args after a colon, such as round:2
+	Transforms []string `json:"x-transform,omitempty"`
+	// Unit is the canonical unit numbers are converted to
+	Unit string `json:"x-unit,omitempty"`
+	// UnitFrom is the sibling key holding the unit a number was sent in
+	UnitFrom string `json:"x-unit-from,omitempty"`
+	// SourceUnit is the unit numbers are sent in when no sibling tells,
+	// usually set by the overlay of an mls
+	SourceUnit string `json:"x-source-unit,omitempty"`
+
+	// annotations, they do not change what is valid but are collected by
+	// EvalWith when asked to
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Examples    []any  `json:"examples,omitempty"`
+	// Deprecated properties are still validated, but sending them is
+	// reported as a warning
+	Deprecated bool `json:"deprecated,omitempty"`
+	ReadOnly   bool `json:"readOnly,omitempty"`
+
+	// Dialect is the $schema uri of the document. Load normalizes the
+	// keywords of older drafts, so it is only informative
+	Dialect string `json:"$schema,omitempty"`
+
+	// Version identifies the schema document, such as a hash of its
+	// content, and is stamped on everything validated against it
+	Version string `json:"-"`
+
+	// ref is the schema Ref points to, resolved by Compile
+	ref *Schema
+	// keys maps folded property names and aliases to property names,
+	// built by Compile
+	keys map[string]string
 }
 
 func coerceType(v string, toType Type) (any, error) {
@@ -39,56 +138,77 @@ func coerceType(v string, toType Type) (any, error) {
 	// return first coerce that works
 	var err error
 	for _, t := range toType {
-		switch t {
-		case "null": // if we want value to be null, just set to nil
-			return nil, nil
-		case "boolean": // only parse stringifed true and false
-			if v == "true" {
-				return true, nil
-			} else if v == "false" {
-				return false, nil
-			}
-			err = errors.Join(errors.New(fmt.Sprintf("failed to parse value <%s> to boolean", v)), err)
-		case "integer": // try to parse integer first as it is more specific than number
-			intVal, intCoerceError := strconv.Atoi(v)
-			if intCoerceError == nil {
-				return intVal, nil
-			}
-			err = errors.Join(errors.New(fmt.Sprintf("failed to parse value <%s> to integer: %s", v, intCoerceError)), err)
-		case "number":
-			intVal, intCoerceError := strconv.Atoi(v)
-			if intCoerceError == nil {
-				return intVal, nil
-			}
-			err = errors.Join(errors.New(fmt.Sprintf("failed to parse value <%s> to integer: %s", v, intCoerceError)), err)
-			floatVal, floatCoerceError := strconv.ParseFloat(v, 64)
-			if floatCoerceError == nil { // try parse float
-				return floatVal, nil
-			}
-			err = errors.Join(errors.New(fmt.Sprintf("failed to parse value <%s> to float: %s", v, floatCoerceError)), err)
-		case "array":
-			var arr []any
-			arrCoerceError := json.Unmarshal([]byte(v), &arr)
-			if arrCoerceError == nil {
-				return arr, nil
-			}
-			err = errors.Join(errors.New(fmt.Sprintf("failed to parse value <%s> to array: %s", v, arrCoerceError)), err)
-		case "object":
-			var obj map[string]any
-			objCoerceError := json.Unmarshal([]byte(v), &obj)
-			if objCoerceError == nil {
-				return obj, nil
-			}
-			err = errors.Join(errors.New(fmt.Sprintf("failed to parse value <%s> to obj: %s", v, objCoerceError)), err)
-		default:
+		c, ok := lookupCoercer(t)
+		if !ok {
 			err = errors.Join(errors.New(fmt.Sprintf("cannot coerce value <%s> to unknown type <%s>", v, t)), err)
+			continue
+		}
+		val, coerceErr := c(v)
+		if coerceErr == nil {
+			return val, nil
 		}
-	}	
+		err = errors.Join(coerceErr, err)
+	}
 	return nil, err
 }
 
 func (s *Schema) Eval(v any, coerce bool) (any, error) {
+	return s.EvalWith(v, EvalOptions{Coerce: coerce})
+}
+
+// EvalWith evaluates v like Eval, with the options of opts
+func (s *Schema) EvalWith(v any, opts EvalOptions) (any, error) {
+	// a missing schema constrains nothing
+	if s == nil {
+		return v, nil
+	}
+	v, err := s.eval(v, opts)
+	// only schemas the value is valid against annotate it
+	if err == nil || IsWarning(err) {
+		s.annotate(opts)
+	}
+	return v, err
+}
+
+// nulled returns the null value of a transform along with the warnings
+// collected before it, a value nulled has nothing left to check
+func nulled(warns []error) (any, error) {
+	if len(warns) > 0 {
+		return nil, &Warning{Err: errors.Join(warns...)}
+	}
+	return nil, nil
+}
+
+func (s *Schema) eval(v any, opts EvalOptions) (any, error) {
 	var err error
+	// warnings collected from this node and its children, if any
+	// are found the value is still valid and returned along them
+	warns := []error{}
+	// handle $ref, the referenced schema applies alongside this one
+	if s.Ref != "" {
+		if s.ref == nil {
+			return v, fmt.Errorf("unresolved $ref <%s>, the schema must be compiled first", s.Ref)
+		}
+		v, err = s.ref.EvalWith(v, opts)
+		if IsWarning(err) {
+			warns = append(warns, err)
+		} else if err != nil {
+			return v, err
+		}
+	}
+	// handle transforms of strings before the type check, so a value
+	// such as " 123 " is trimmed before it is coerced
+	transformed := false
+	if _, ok := v.(string); ok && s.Transforms != nil {
+		v, err = evalTransforms(s, v)
+		if err != nil {
+			return v, err
+		}
+		if v == nil {
+			return nulled(warns)
+		}
+		transformed = true
+	}
 	// handle base type check
 	if s.Type != nil {
 		valType, err := evalType(s, v)
@@ -96,7 +216,7 @@ func (s *Schema) Eval(v any, coerce bool) (any, error) {
 			// if we do not want to coerce OR no valType was returned
 			// OR valType is not a string return original eval error
 			// since we will not try to coerce a non string type
-			if !coerce || valType == "" || valType != "string" {
+			if !opts.Coerce || valType == "" || valType != "string" {
 				return v, err
 			}
 
@@ -106,30 +226,101 @@ func (s *Schema) Eval(v any, coerce bool) (any, error) {
 			var coerceErr error
 			v, coerceErr = coerceType(v.(string), s.Type)	
 			if coerceErr != nil {
-				return v, errors.Join(err, coerceErr)
+				return v, errors.Join(err, ErrCoerce, coerceErr)
 			}
 
 			// if we do coerceType, do nothing since `v` is now
 			// correct type
 		}
 	}
-	// handle array
-	if s.Items != nil {
-		v, err = evalArray(s, v, coerce)
+	// handle units, converting numbers from the unit they were sent in
+	if s.Unit != "" {
+		v, err = evalUnit(s, v, opts)
 		if err != nil {
+			return v, err
+		}
+	}
+	// handle transforms of other values, and again of strings that were
+	// coerced, before anything is asserted on them
+	if _, ok := v.(string); s.Transforms != nil && (!transformed || !ok) {
+		v, err = evalTransforms(s, v)
+		if err != nil {
+			return v, err
+		}
+		if v == nil {
+			return nulled(warns)
+		}
+	}
+	// handle value assertions, downgrading them to warnings if the
+	// node is annotated as such
+	if err = evalAssertions(s, v); err != nil {
+		if s.Severity != SeverityWarning {
+			return v, err
+		}
+		warns = append(warns, err)
+	}
+	// handle anyOf, the first branch the value is valid against wins
+	// and its possibly coerced value is used
+	if s.AnyOf != nil {
+		v, err = evalAnyOf(s, v, opts)
+		if IsWarning(err) {
+			warns = append(warns, err)
+		} else if err != nil {
+			return v, err
+		}
+	}
+	// handle array
+	if s.Items != nil || s.PrefixItems != nil {
+		v, err = evalArray(s, v, opts)
+		if IsWarning(err) {
+			warns = append(warns, err)
+		} else if err != nil {
 			return nil, err
 		}
 	}
 	// handle properties
-	if s.Properties != nil {
-		v, err = evalObject(s, v, coerce)
-		if err != nil {
+	if s.constrainsKeys() || s.Required != nil {
+		v, err = evalObject(s, v, opts)
+		if IsWarning(err) {
+			warns = append(warns, err)
+		} else if err != nil && opts.Partial {
+			return v, err
+		} else if err != nil {
 			return nil, err
 		}
 	}
+	if len(warns) > 0 {
+		return v, &Warning{Err: errors.Join(warns...)}
+	}
 	return v, nil
 }
 
+func evalAnyOf(s *Schema, val any, opts EvalOptions) (any, error) {
+	errs := []error{}
+	// annotations are held back until a branch is known to match, as
+	// parts of the value can be valid against a branch that fails
+	var held []Annotation
+	branchOpts := opts
+	if opts.Annotate != nil {
+		branchOpts.Annotate = func(a Annotation) { held = append(held, a) }
+	}
+	for i, branch := range s.AnyOf {
+		if branch == nil {
+			continue
+		}
+		held = nil
+		v, err := branch.EvalWith(val, branchOpts)
+		if err == nil || IsWarning(err) {
+			for _, a := range held {
+				opts.Annotate(a)
+			}
+			return v, err
+		}
+		errs = append(errs, fmt.Errorf("branch %d: %w", i, err))
+	}
+	return val, errors.Join(append([]error{fmt.Errorf("%w: %v", ErrAnyOf, val)}, errs...)...)
+}
+
 func evalType(s *Schema, val any) (string, error) {
 	if s == nil {
 		return "", errors.New("schema is nil, cannot eval type")
@@ -137,7 +328,6 @@ func evalType(s *Schema, val any) (string, error) {
 
 	// if no type specified, accept it as is
 	if len(s.Type) == 0 {
-		fmt.Printf("no type specified\n")
 		return "", nil
 	}
 
@@ -145,16 +335,16 @@ func evalType(s *Schema, val any) (string, error) {
 	// schema types
 	valType := GetDataType(val)
 	for _, t := range s.Type {
-		if valType == t {
+		if MatchesType(val, t) {
 			return valType, nil
 		}
 	}
 
 	// if no matches, return error specifying type mismatch
-	return valType, errors.New(fmt.Sprintf("type mismatch, the value <%v> has the type <%s> which does not match expected type(s) <%v>", val, valType, s.Type))
+	return valType, fmt.Errorf("%w, the value <%v> has the type <%s> which does not match expected type(s) <%v>", ErrTypeMismatch, val, valType, s.Type)
 }
 
-func evalObject(s *Schema, val any, coerce bool) (any, error) {
+func evalObject(s *Schema, val any, opts EvalOptions) (any, error) {
 	if s == nil {
 		return nil, errors.New("schema is nil, cannot eval object")
 	}
@@ -164,16 +354,41 @@ func evalObject(s *Schema, val any, coerce bool) (any, error) {
 	// ensure val is of object type
 	valObj, ok := val.(map[string]any)
 	if !ok {
-		return nil, errors.New(fmt.Sprintf("value <%v> cannot be evaluated as an array", val))
+		return nil, errors.New(fmt.Sprintf("value <%v> cannot be evaluated as an object", val))
+	}
+
+	// ensure every required property is present, the properties are
+	// still evaluated so the failures of the keys sent are known too
+	missing := []string{}
+	for _, k := range s.Required {
+		if _, ok := valObj[k]; !ok {
+			missing = append(missing, k)
+		}
+	}
+	var requiredErr error
+	if len(missing) > 0 {
+		requiredErr = fmt.Errorf("%w(s) <%v>", ErrRequired, missing)
 	}
 
 	// if properties is specified by schema, evaluate them
-	if s.Properties != nil {
-		val, err = evalProperties(s, valObj, coerce)
-		if err != nil {
-			// if unable to evaluate all properties, return error
-			return val, errors.Join(fmt.Errorf("failed to validate object: %v", valObj), err)
+	if s.constrainsKeys() {
+		val, err = evalProperties(s, valObj, opts)
+		if IsWarning(err) && requiredErr == nil {
+			return val, err
+		}
+	}
+	if requiredErr != nil {
+		// the warnings of the keys are kept by key, without exposing
+		// them to errors.Is as the object fails
+		var pe *PropertiesError
+		if IsWarning(err) && errors.As(err, &pe) {
+			err = &PropertiesError{Keys: pe.Keys}
 		}
+		return val, errors.Join(fmt.Errorf("%w: %v", ErrInvalidObject, valObj), requiredErr, err)
+	}
+	if err != nil {
+		// if unable to evaluate all properties, return error
+		return val, errors.Join(fmt.Errorf("%w: %v", ErrInvalidObject, valObj), err)
 	}
 
 	// else return val if no properties are specified meaning the obj type can contain anything
@@ -181,42 +396,91 @@ func evalObject(s *Schema, val any, coerce bool) (any, error) {
 
 }
 
-func evalProperties(s *Schema, val map[string]any, coerce bool) (any, error) {
+func evalProperties(s *Schema, val map[string]any, opts EvalOptions) (any, error) {
 	if s == nil {
 		return nil, errors.New("\tschema is nil, cannot eval properties")
 	}
 
-	if s.Properties == nil {
+	if !s.constrainsKeys() {
 		fmt.Printf("no object schema specified\n")
 		return val, nil
 	}
 
+	// keys are siblings of one another by property name, so they are
+	// found when sent under an alias
+	siblings := make(map[string]any, len(val))
+	for _, objK := range sortedKeys(val) {
+		name, _, _ := s.Schemas(objK)
+		if _, ok := siblings[name]; !ok || name == objK {
+			siblings[name] = val[objK]
+		}
+	}
+
 	// for each key:value in object
 	validKeyVals := map[string]any{}
-	errs := []error{}
+	errs := map[string]error{}
+	warns := map[string]error{}
+	// aliased keys renamed to their property, by property
+	renamed := map[string]string{}
+	duplicates := false
 	for objK, objV := range val {
-		if _, ok := (*s.Properties)[objK]; !ok {
+		if err := s.EvalName(objK); err != nil {
+			errs[objK] = err
+			continue
+		}
+		name, schemas, ok := s.Schemas(objK)
+		if !ok && s.AdditionalProperties != nil {
+			schemas = []*Schema{s.AdditionalProperties}
+		}
+		if name != objK {
+			// an aliased key sent along another key of the same property
+			// would overwrite it, so neither is kept
+			other, dup := renamed[name]
+			if _, ok := val[name]; ok {
+				other, dup = name, true
+			}
+			if dup {
+				errs[objK] = fmt.Errorf("%w, it is an alias of <%s> also sent as <%s>", ErrDuplicateKey, name, other)
+				duplicates = true
+				continue
+			}
+			renamed[name] = objK
+		}
+		if len(schemas) == 0 {
 			// if obj key not specified in properties schema, add to validKeyVals and continue
 			// as no schema was specified
-			validKeyVals[objK] = objV
+			validKeyVals[name] = objV
 			continue
 		}
 		// otherwise, attempt to evaluate the key:value as per schema spec
-		v, err := (*s.Properties)[objK].Eval(objV, coerce)
-		if err != nil {
-			errs = append(errs, err)
+		v, err := EvalAll(schemas, objV, opts.child(name, siblings))
+		if depErr := deprecated(name, schemas); depErr != nil && (err == nil || IsWarning(err)) {
+			err = &Warning{Err: errors.Join(depErr, err)}
+		}
+		if IsWarning(err) {
+			warns[objK] = err
+		} else if err != nil {
+			errs[objK] = err
 			continue
 		}
-		validKeyVals[objK] = v
+		validKeyVals[name] = v
 	}
 
 	if len(errs) > 0 {
-		return validKeyVals, errors.New(fmt.Sprintf("\tcould not validate all key:vals in obj: %v", errs))
+		// the warnings of the other keys are kept along, still wrapped in
+		// their Warning
+		for k, w := range warns {
+			errs[k] = w
+		}
+		return validKeyVals, &PropertiesError{Keys: errs, duplicate: duplicates}
+	}
+	if len(warns) > 0 {
+		return validKeyVals, &Warning{Err: &PropertiesError{Keys: warns, warning: true}}
 	}
 	return validKeyVals, nil
 }
 
-func evalArray(s *Schema, val any, coerce bool) (any, error) {
+func evalArray(s *Schema, val any, opts EvalOptions) (any, error) {
 	if s == nil {
 		return nil, errors.New("schema is nil, cannot eval array")
 	}
@@ -229,44 +493,73 @@ func evalArray(s *Schema, val any, coerce bool) (any, error) {
 	}
 
 	// enture items are correct schema if schema specifies one
-	if s.Items != nil {
-		val, err = evalItems(s, valItems, coerce)
+	if s.Items != nil || s.PrefixItems != nil {
+		val, err = evalItems(s, valItems, opts)
+		if IsWarning(err) {
+			return val, err
+		}
 		if err != nil {
-			return val, errors.Join(fmt.Errorf("failed to validate all items in array: %v", valItems), err)
+			return val, errors.Join(fmt.Errorf("%w: %v", ErrInvalidItems, valItems), err)
 		}
 	}
 
 	return val, nil
 }
 
-func evalItems(s *Schema, valItems []any, coerce bool) ([]any, error) {
+func evalItems(s *Schema, valItems []any, opts EvalOptions) ([]any, error) {
 	if s == nil {
 		return nil, errors.New("\tschema is nil, cannot eval Items")
 	}
 
-	if s.Items == nil {
+	if s.Items == nil && s.PrefixItems == nil {
 		fmt.Printf("no item schema specified\n")
 		return valItems, nil
 	}
 
 	validItems := []any{}
-	errs := []error{}
+	errs := map[int]error{}
+	warns := map[int]error{}
 
 	for i := range valItems {
-		v, err := s.Items.Eval(valItems[i], coerce)
-		if err != nil {
-			errs = append(errs, err)
+		item := s.Items
+		if i < len(s.PrefixItems) {
+			item = s.PrefixItems[i]
+		}
+		v, err := item.EvalWith(valItems[i], opts.child(strconv.Itoa(i), nil))
+		if IsWarning(err) {
+			warns[i] = err
+		} else if err != nil {
+			errs[i] = err
             continue
         }
         validItems = append(validItems, v)
 	}
 
 	if len(errs) > 0 {
-		return nil, errors.New(fmt.Sprintf("\terror: could not validate all items in array: %v", errs))
+		return nil, &ItemsError{Items: errs}
+	}
+	if len(warns) > 0 {
+		return validItems, &Warning{Err: &ItemsError{Items: warns, warning: true}}
 	}
 	return validItems, nil
 }
 
+// MatchesType reports whether v is of the json schema type t. integers
+// are numbers, and whole numbers are integers whatever their go type
+func MatchesType(v any, t string) bool {
+	valType := GetDataType(v)
+	switch {
+	case valType == t:
+		return true
+	case t == "number":
+		return valType == "integer"
+	case t == "integer" && valType == "number":
+		n, _ := toFloat(v)
+		return n == math.Trunc(n) && !math.IsInf(n, 0)
+	}
+	return false
+}
+
 func GetDataType(v interface{}) string {
 	switch v.(type) {
 	case nil:
@@ -0,0 +1,159 @@
+package schema
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMatchesType(t *testing.T) {
+	testCases := []struct {
+		description string
+		value       any
+		schemaType  string
+		expected    bool
+	}{
+		{
+			description: "it should match a whole float as an integer",
+			value:       1.0,
+			schemaType:  "integer",
+			expected:    true,
+		},
+		{
+			description: "it should not match a fractional float as an integer",
+			value:       1.5,
+			schemaType:  "integer",
+			expected:    false,
+		},
+		{
+			description: "it should not match an infinite float as an integer",
+			value:       math.Inf(1),
+			schemaType:  "integer",
+			expected:    false,
+		},
+		{
+			description: "it should match a go integer as a number",
+			value:       1,
+			schemaType:  "number",
+			expected:    true,
+		},
+		{
+			description: "it should match a go integer as an integer",
+			value:       int64(1),
+			schemaType:  "integer",
+			expected:    true,
+		},
+		{
+			description: "it should not match a numeric string as an integer",
+			value:       "1",
+			schemaType:  "integer",
+			expected:    false,
+		},
+		{
+			description: "it should match nil as null",
+			value:       nil,
+			schemaType:  "null",
+			expected:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		if matches := MatchesType(testCase.value, testCase.schemaType); matches != testCase.expected {
+			t.Fatalf("%s: expected <%t>, got <%t>", testCase.description, testCase.expected, matches)
+		}
+	}
+}
+
+func TestEvalWholeNumbersAsIntegers(t *testing.T) {
+	s, err := Load(strings.NewReader(`{ "type": "integer" }`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	if v, err := s.Eval(3.0, false); err != nil || v != 3.0 {
+		t.Fatalf("expected a whole float to be accepted as is, got <%v> and error <%v>", v, err)
+	}
+	if _, err := s.Eval(3.5, false); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected a type mismatch for a fractional float, got <%v>", err)
+	}
+}
+
+func TestExclusiveBounds(t *testing.T) {
+	s, err := Load(strings.NewReader(`{ "type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 10 }`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	testCases := []struct {
+		description string
+		value       any
+		expectedErr error
+	}{
+		{
+			description: "it should accept a value between the bounds",
+			value:       5.0,
+		},
+		{
+			description: "it should reject a value equal to the exclusive minimum",
+			value:       0.0,
+			expectedErr: ErrRange,
+		},
+		{
+			description: "it should reject a value equal to the exclusive maximum",
+			value:       10.0,
+			expectedErr: ErrRange,
+		},
+		{
+			description: "it should reject a value above the exclusive maximum",
+			value:       11.0,
+			expectedErr: ErrRange,
+		},
+	}
+
+	for _, testCase := range testCases {
+		if _, err := s.Eval(testCase.value, false); !errors.Is(err, testCase.expectedErr) {
+			t.Fatalf("%s: expected error <%v>, got <%v>", testCase.description, testCase.expectedErr, err)
+		}
+	}
+}
+
+func TestPrefixItems(t *testing.T) {
+	testCases := []struct {
+		description string
+		schema      string
+		value       []any
+		expectedErr error
+	}{
+		{
+			description: "it should validate items by position",
+			schema:      `{ "type": "array", "prefixItems": [ { "type": "string" }, { "type": "number" } ] }`,
+			value:       []any{"ListPrice", 100.0},
+		},
+		{
+			description: "it should reject an item not matching its position",
+			schema:      `{ "type": "array", "prefixItems": [ { "type": "string" }, { "type": "number" } ] }`,
+			value:       []any{"ListPrice", "100"},
+			expectedErr: ErrInvalidItems,
+		},
+		{
+			description: "it should accept any item after the prefix without items",
+			schema:      `{ "type": "array", "prefixItems": [ { "type": "string" } ] }`,
+			value:       []any{"ListPrice", true},
+		},
+		{
+			description: "it should apply items after the prefix",
+			schema:      `{ "type": "array", "prefixItems": [ { "type": "string" } ], "items": { "type": "number" } }`,
+			value:       []any{"ListPrice", true},
+			expectedErr: ErrInvalidItems,
+		},
+	}
+
+	for _, testCase := range testCases {
+		s, err := Load(strings.NewReader(testCase.schema))
+		if err != nil {
+			t.Fatalf("%s: failed to load schema: %s", testCase.description, err)
+		}
+		if _, err := s.Eval(testCase.value, false); !errors.Is(err, testCase.expectedErr) {
+			t.Fatalf("%s: expected error <%v>, got <%v>", testCase.description, testCase.expectedErr, err)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// suiteDir holds the draft 2020-12 files of the official
+// JSON-Schema-Test-Suite, one file per keyword
+var suiteDir = filepath.Join("testdata", "JSON-Schema-Test-Suite", "tests", "draft2020-12")
+
+var suiteReport = flag.String("suite.report", "", "write the per-keyword test suite report as json to this file")
+
+// suiteSkip lists the cases that are not expected to pass yet along with
+// the reason, keyed by keyword, keyword and group description, or
+// keyword, group and case description. skipped cases still run, their
+// failures are counted as skipped in the report rather than failing the
+// test, and an entry none of whose cases fails anymore fails the test so
+// the list does not outlive the gaps it documents
+var suiteSkip = map[string]string{
+	"allOf":                   "allOf is not supported",
+	"anchor":                  "$anchor and $id are not supported",
+	"boolean_schema":          "boolean schemas are not supported",
+	"const":                   "const is not supported",
+	"contains":                "contains is not supported",
+	"defs":                    "$ref to the remote meta-schema is not supported",
+	"dependentRequired":       "dependentRequired is not supported",
+	"dependentSchemas":        "dependentSchemas is not supported",
+	"dynamicRef":              "$dynamicRef and $dynamicAnchor are not supported",
+	"if-then-else":            "if, then and else are not supported",
+	"infinite-loop-detection": "allOf is not supported",
+	"maxContains":             "contains and maxContains are not supported",
+	"maxItems":                "maxItems is not supported",
+	"maxLength":               "maxLength is not supported",
+	"maxProperties":           "maxProperties is not supported",
+	"minContains":             "contains and minContains are not supported",
+	"minItems":                "minItems is not supported",
+	"minLength":               "minLength is not supported",
+	"minProperties":           "minProperties is not supported",
+	"multipleOf":              "multipleOf is not supported",
+	"not":                     "not is not supported",
+	"oneOf":                   "oneOf is not supported",
+	"pattern":                 "pattern is not supported",
+	"refRemote":               "remote $ref is not supported",
+	"unevaluatedItems":        "unevaluatedItems is not supported",
+	"unevaluatedProperties":   "unevaluatedProperties is not supported",
+	"uniqueItems":             "uniqueItems is not supported",
+
+	"additionalProperties/additionalProperties being false does not allow other properties": "boolean schemas are not supported",
+	"additionalProperties/non-ASCII pattern with additionalProperties":                      "boolean schemas are not supported",
+	"additionalProperties/dependentSchemas with additionalProperties":                       "boolean schemas and dependentSchemas are not supported",
+	"anyOf/anyOf with base schema":                                                          "maxLength and minLength are not supported",
+	"anyOf/anyOf with boolean schemas, all true":                                            "boolean schemas are not supported",
+	"anyOf/anyOf with boolean schemas, some true":                                           "boolean schemas are not supported",
+	"anyOf/anyOf with boolean schemas, all false":                                           "boolean schemas are not supported",
+	"items/items with boolean schema (true)":                                                "boolean schemas are not supported",
+	"items/items with boolean schema (false)":                                               "boolean schemas are not supported",
+	"items/items and subitems":                                                              "boolean schemas are not supported",
+	"items/prefixItems with no additional items allowed":                                    "boolean schemas are not supported",
+	"items/items with heterogeneous array":                                                  "boolean schemas are not supported",
+	"patternProperties/patternProperties with boolean schemas":                              "boolean schemas are not supported",
+	"prefixItems/prefixItems with boolean schemas":                                          "boolean schemas are not supported",
+	"properties/properties with boolean schema":                                             "boolean schemas are not supported",
+	"properties/properties, patternProperties, additionalProperties interaction":            "maxItems and minItems are not supported",
+	"propertyNames/propertyNames validation":                                                "maxLength is not supported",
+	"propertyNames/propertyNames validation with pattern":                                   "pattern is not supported",
+	"propertyNames/propertyNames with boolean schema true":                                  "boolean schemas are not supported",
+	"propertyNames/propertyNames with boolean schema false":                                 "boolean schemas are not supported",
+	"propertyNames/propertyNames with const":                                                "const is not supported",
+
+	"ref/root pointer ref":                                                       "boolean schemas are not supported",
+	"ref/$ref to boolean schema true":                                            "boolean schemas are not supported",
+	"ref/$ref to boolean schema false":                                           "boolean schemas are not supported",
+	"ref/escaped pointer ref":                                                    "$ref pointers are not percent-decoded",
+	"ref/refs with quote":                                                        "$ref pointers are not percent-decoded",
+	"ref/ref applies alongside sibling keywords":                                 "maxItems is not supported",
+	"ref/remote ref, containing refs itself":                                     "remote $ref is not supported",
+	"ref/Recursive references between schemas":                                   "$id is not supported",
+	"ref/refs with relative uris and defs":                                       "$id is not supported",
+	"ref/relative refs with absolute uris and defs":                              "$id is not supported",
+	"ref/$id must be resolved against nearest parent, not just immediate parent": "$id is not supported",
+	"ref/order of evaluation: $id and $ref":                                      "$id is not supported",
+	"ref/order of evaluation: $id and $anchor and $ref":                          "$id and $anchor are not supported",
+	"ref/simple URN base URI with $ref via the URN":                              "$id is not supported",
+	"ref/URN base URI with URN and JSON pointer ref":                             "$id is not supported",
+	"ref/URN base URI with URN and anchor ref":                                   "$id and $anchor are not supported",
+	"ref/URN ref with nested pointer ref":                                        "$id is not supported",
+	"ref/ref to if":                                                              "$id and if are not supported",
+	"ref/ref to then":                                                            "$id and then are not supported",
+	"ref/ref to else":                                                            "$id and else are not supported",
+	"ref/ref with absolute-path-reference":                                       "$id is not supported",
+	"ref/ref creates new scope when adjacent to keywords":                        "unevaluatedProperties is not supported",
+	"ref/empty tokens in $ref json-pointer":                                      "allOf is not supported",
+
+	"items/a schema given for items/ignores non-arrays":                                                       "items requires the value to be an array",
+	"items/a schema given for items/JavaScript pseudo-array is valid":                                         "items requires the value to be an array",
+	"prefixItems/a schema given for prefixItems/JavaScript pseudo-array is valid":                             "prefixItems requires the value to be an array",
+	"patternProperties/patternProperties validates properties matching a regex/ignores arrays":                "patternProperties requires the value to be an object",
+	"patternProperties/patternProperties validates properties matching a regex/ignores strings":               "patternProperties requires the value to be an object",
+	"patternProperties/patternProperties validates properties matching a regex/ignores other non-objects":     "patternProperties requires the value to be an object",
+	"properties/object properties validation/ignores arrays":                                                  "properties requires the value to be an object",
+	"properties/object properties validation/ignores other non-objects":                                       "properties requires the value to be an object",
+	"properties/properties whose names are Javascript object property names/ignores arrays":                   "properties requires the value to be an object",
+	"properties/properties whose names are Javascript object property names/ignores other non-objects":        "properties requires the value to be an object",
+	"required/required validation/ignores arrays":                                                             "required requires the value to be an object",
+	"required/required validation/ignores strings":                                                            "required requires the value to be an object",
+	"required/required validation/ignores other non-objects":                                                  "required requires the value to be an object",
+	"required/required properties whose names are Javascript object property names/ignores arrays":            "required requires the value to be an object",
+	"required/required properties whose names are Javascript object property names/ignores other non-objects": "required requires the value to be an object",
+}
+
+type suiteGroup struct {
+	Description string          `json:"description"`
+	Schema      json.RawMessage `json:"schema"`
+	Tests       []suiteCase     `json:"tests"`
+}
+
+type suiteCase struct {
+	Description string          `json:"description"`
+	Data        json.RawMessage `json:"data"`
+	Valid       bool            `json:"valid"`
+}
+
+// suiteTally counts the cases of a keyword, failures on the skip list
+// are counted as skipped
+type suiteTally struct {
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+func TestSuite(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join(suiteDir, "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("failed to find test suite files in <%s>: %v", suiteDir, err)
+	}
+	sort.Strings(files)
+
+	tallies := map[string]*suiteTally{}
+	// failing counts the failed cases of each suiteSkip entry
+	failing := map[string]int{}
+	for k := range suiteSkip {
+		failing[k] = 0
+	}
+	for _, file := range files {
+		keyword := strings.TrimSuffix(filepath.Base(file), ".json")
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read <%s>: %s", file, err)
+		}
+		var groups []suiteGroup
+		if err := json.Unmarshal(data, &groups); err != nil {
+			t.Fatalf("failed to parse <%s>: %s", file, err)
+		}
+
+		tally := &suiteTally{}
+		tallies[keyword] = tally
+		for _, group := range groups {
+			// a schema this package cannot load fails every case of its group
+			s, loadErr := Load(bytes.NewReader(group.Schema))
+			for _, c := range group.Tests {
+				ok := false
+				if loadErr == nil {
+					res, err := Validate(c.Data, s)
+					ok = err == nil && res.Valid == c.Valid
+				}
+				entry, skip := suiteSkipEntry(keyword, group.Description, c.Description)
+				switch {
+				case ok:
+					tally.Passed++
+				case skip:
+					tally.Skipped++
+					failing[entry]++
+				default:
+					tally.Failed++
+					if loadErr != nil {
+						t.Errorf("%s/%s/%s: failed to load schema: %s", keyword, group.Description, c.Description, loadErr)
+					} else {
+						t.Errorf("%s/%s/%s: expected valid <%t>", keyword, group.Description, c.Description, c.Valid)
+					}
+				}
+			}
+		}
+	}
+
+	stale := []string{}
+	for k, n := range failing {
+		if n == 0 {
+			stale = append(stale, k)
+		}
+	}
+	sort.Strings(stale)
+	for _, k := range stale {
+		t.Errorf("%s: no skipped case fails anymore, remove it from suiteSkip", k)
+	}
+
+	keywords := make([]string, 0, len(tallies))
+	for k := range tallies {
+		keywords = append(keywords, k)
+	}
+	sort.Strings(keywords)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-24s %6s %6s %7s\n", "keyword", "passed", "failed", "skipped")
+	for _, k := range keywords {
+		fmt.Fprintf(&b, "%-24s %6d %6d %7d\n", k, tallies[k].Passed, tallies[k].Failed, tallies[k].Skipped)
+	}
+	t.Logf("test suite report:\n%s", b.String())
+
+	if *suiteReport != "" {
+		data, err := json.MarshalIndent(tallies, "", "  ")
+		if err != nil {
+			t.Fatalf("failed to marshal report: %s", err)
+		}
+		if err := os.WriteFile(*suiteReport, data, 0o644); err != nil {
+			t.Fatalf("failed to write report: %s", err)
+		}
+	}
+}
+
+// suiteSkipEntry returns the most specific suiteSkip entry covering a case
+func suiteSkipEntry(keyword, group, description string) (string, bool) {
+	for _, k := range []string{keyword + "/" + group + "/" + description, keyword + "/" + group, keyword} {
+		if _, ok := suiteSkip[k]; ok {
+			return k, true
+		}
+	}
+	return "", false
+}
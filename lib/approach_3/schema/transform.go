@@ -0,0 +1,124 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Transform normalizes a value. args is the text after the colon of the
+// transform in `x-transform`, 2 for round:2, and empty without one.
+// values a transform does not apply to are returned unchanged. strings
+// are transformed before they are coerced, and again once coerced
+type Transform func(v any, args string) (any, error)
+
+var (
+	transformsMu sync.RWMutex
+	transforms   = map[string]Transform{
+		"trim":               transformString(strings.TrimSpace),
+		"upper":              transformString(strings.ToUpper),
+		"lower":              transformString(strings.ToLower),
+		"collapseWhitespace": transformString(func(v string) string { return strings.Join(strings.Fields(v), " ") }),
+		"nullIfEmpty":        nullIfEmpty,
+		"round":              round,
+	}
+)
+
+// RegisterTransform makes a transform available to `x-transform` under
+// name, replacing any transform of the same name. transforms must be
+// registered before compiling schemas using them
+func RegisterTransform(name string, t Transform) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+	transforms[name] = t
+}
+
+func lookupTransform(name string) (Transform, bool) {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+	t, ok := transforms[name]
+	return t, ok
+}
+
+// parseTransform splits an `x-transform` entry into its name and args
+func parseTransform(entry string) (string, string) {
+	name, args, _ := strings.Cut(entry, ":")
+	return name, args
+}
+
+// checkTransforms reports the transforms of s which are not registered
+func (s *Schema) checkTransforms() error {
+	for _, entry := range s.Transforms {
+		name, _ := parseTransform(entry)
+		if _, ok := lookupTransform(name); !ok {
+			return fmt.Errorf("unknown transform <%s>", name)
+		}
+	}
+	return nil
+}
+
+// evalTransforms runs the transforms of s over v in order
+func evalTransforms(s *Schema, v any) (any, error) {
+	for _, entry := range s.Transforms {
+		name, args := parseTransform(entry)
+		t, ok := lookupTransform(name)
+		if !ok {
+			return v, fmt.Errorf("%w, unknown transform <%s>", ErrTransform, name)
+		}
+		out, err := t(v, args)
+		if err != nil {
+			return v, fmt.Errorf("%w <%s> of value <%v>: %w", ErrTransform, entry, v, err)
+		}
+		v = out
+	}
+	return v, nil
+}
+
+// transformString turns a string function into a transform leaving
+// other values alone
+func transformString(fn func(string) string) Transform {
+	return func(v any, args string) (any, error) {
+		if str, ok := v.(string); ok {
+			return fn(str), nil
+		}
+		return v, nil
+	}
+}
+
+// nullIfEmpty turns empty strings, arrays and objects into null
+func nullIfEmpty(v any, args string) (any, error) {
+	switch val := v.(type) {
+	case string:
+		if val == "" {
+			return nil, nil
+		}
+	case []any:
+		if len(val) == 0 {
+			return nil, nil
+		}
+	case map[string]any:
+		if len(val) == 0 {
+			return nil, nil
+		}
+	}
+	return v, nil
+}
+
+// round rounds floats to the number of decimals in args, 0 by default
+func round(v any, args string) (any, error) {
+	decimals := 0
+	if args != "" {
+		var err error
+		if decimals, err = strconv.Atoi(args); err != nil || decimals < 0 {
+			return v, fmt.Errorf("decimals <%s> are not a non-negative integer", args)
+		}
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return v, nil
+	}
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(f*scale) / scale, nil
+}
@@ -0,0 +1,124 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTransforms(t *testing.T) {
+	RegisterTransform("prefix", func(v any, args string) (any, error) {
+		if s, ok := v.(string); ok {
+			return args + s, nil
+		}
+		return v, nil
+	})
+
+	testCases := []struct {
+		description string
+		schema      string
+		input       any
+		coerce      bool
+		expected    any
+		expectedErr error
+	}{
+		{
+			description: "it should normalize strings before checking the enum",
+			schema:      `{ "type": "string", "x-transform": ["trim", "collapseWhitespace", "upper"], "enum": ["LOS ANGELES"] }`,
+			input:       "  los   Angeles ",
+			expected:    "LOS ANGELES",
+		},
+		{
+			description: "it should round coerced numbers before checking the maximum",
+			schema:      `{ "type": "number", "x-transform": ["round:2"], "maximum": 1.23 }`,
+			input:       "1.234",
+			coerce:      true,
+			expected:    1.23,
+		},
+		{
+			description: "it should trim strings before coercing them",
+			schema:      `{ "type": "integer", "x-transform": ["trim"] }`,
+			input:       " 123 ",
+			coerce:      true,
+			expected:    123,
+		},
+		{
+			description: "it should null empty strings before checking their type",
+			schema:      `{ "type": "integer", "x-transform": ["nullIfEmpty"] }`,
+			input:       "",
+			coerce:      true,
+			expected:    nil,
+		},
+		{
+			description: "it should null empty values and skip the remaining keywords",
+			schema:      `{ "type": "string", "x-transform": ["trim", "nullIfEmpty"], "enum": ["Active"] }`,
+			input:       "   ",
+			expected:    nil,
+		},
+		{
+			description: "it should transform nested values",
+			schema:      `{ "type": "object", "properties": { "City": { "type": "string", "x-transform": ["lower"] } } }`,
+			input:       map[string]any{"City": "LA"},
+			expected:    map[string]any{"City": "la"},
+		},
+		{
+			description: "it should run registered transforms with their args",
+			schema:      `{ "type": "string", "x-transform": ["prefix:MLS-"] }`,
+			input:       "1234",
+			expected:    "MLS-1234",
+		},
+		{
+			description: "it should fail on invalid transform args",
+			schema:      `{ "type": "number", "x-transform": ["round:two"] }`,
+			input:       1.5,
+			expectedErr: ErrTransform,
+		},
+	}
+
+	for _, testCase := range testCases {
+		s, err := Load(strings.NewReader(testCase.schema))
+		if err != nil {
+			t.Fatalf("%s: failed to load schema: %s", testCase.description, err)
+		}
+		out, err := s.Eval(testCase.input, testCase.coerce)
+		if testCase.expectedErr != nil {
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("%s: expected error <%s>, got <%v>", testCase.description, testCase.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error <%s>", testCase.description, err)
+		}
+		if !reflect.DeepEqual(out, testCase.expected) {
+			t.Fatalf("%s: output <%v> does not match expected <%v>", testCase.description, out, testCase.expected)
+		}
+	}
+}
+
+func TestLoadUnknownTransform(t *testing.T) {
+	_, err := Load(strings.NewReader(`{ "properties": { "City": { "x-transform": ["titleCase"] } } }`))
+	if err == nil || !strings.Contains(err.Error(), "unknown transform <titleCase>") {
+		t.Fatalf("expected an unknown transform error, got <%v>", err)
+	}
+}
+
+func TestRegisterCoercer(t *testing.T) {
+	RegisterCoercer("boolean", func(v string) (any, error) {
+		switch v {
+		case "Y":
+			return true, nil
+		case "N":
+			return false, nil
+		}
+		return coerceBoolean(v)
+	})
+	defer RegisterCoercer("boolean", coerceBoolean)
+
+	s := &Schema{Type: Type{"boolean"}}
+	out, err := s.Eval("Y", true)
+	if err != nil || out != true {
+		t.Fatalf("expected Y to be coerced to true, got <%v, %v>", out, err)
+	}
+}
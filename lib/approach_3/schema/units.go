@@ -0,0 +1,146 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+// a unit is measured in multiples of the base unit of its dimension
+type unit struct {
+	name      string
+	dimension string
+	factor    float64
+}
+
+var (
+	unitsMu sync.RWMutex
+	// units by lowercased name and alias
+	units = map[string]unit{}
+)
+
+func init() {
+	RegisterUnit("sqft", "area", 1, "sq ft", "square feet", "squarefeet", "ft2")
+	RegisterUnit("sqm", "area", 10.763910416709722, "sq m", "square meters", "squaremeters", "square metres", "m2")
+	RegisterUnit("sqyd", "area", 9, "sq yd", "square yards")
+	RegisterUnit("acres", "area", 43560, "acre", "ac")
+	RegisterUnit("hectares", "area", 107639.10416709722, "hectare", "ha")
+	RegisterUnit("ft", "length", 1, "feet", "foot")
+	RegisterUnit("in", "length", 1.0/12, "inches", "inch")
+	RegisterUnit("yd", "length", 3, "yards", "yard")
+	RegisterUnit("mi", "length", 5280, "miles", "mile")
+	RegisterUnit("m", "length", 3.280839895013123, "meters", "metres", "meter", "metre")
+	RegisterUnit("cm", "length", 0.03280839895013123, "centimeters", "centimetres")
+	RegisterUnit("km", "length", 3280.839895013123, "kilometers", "kilometres")
+}
+
+// RegisterUnit makes a unit usable in `x-unit` and `x-source-unit` under
+// its name and aliases, compared regardless of case. factor is the number
+// of base units of dimension, sqft for area and ft for length, in one unit
+func RegisterUnit(name, dimension string, factor float64, aliases ...string) {
+	unitsMu.Lock()
+	defer unitsMu.Unlock()
+	u := unit{name: name, dimension: dimension, factor: factor}
+	for _, n := range append([]string{name}, aliases...) {
+		units[strings.ToLower(n)] = u
+	}
+}
+
+func lookupUnit(name string) (unit, bool) {
+	unitsMu.RLock()
+	defer unitsMu.RUnlock()
+	u, ok := units[strings.ToLower(strings.TrimSpace(name))]
+	return u, ok
+}
+
+// checkUnits reports unknown units and source units which cannot be
+// converted to the unit of s
+func (s *Schema) checkUnits() error {
+	if s.Unit == "" {
+		if s.UnitFrom != "" || s.SourceUnit != "" {
+			return fmt.Errorf("x-unit-from and x-source-unit require an x-unit")
+		}
+		return nil
+	}
+	to, ok := lookupUnit(s.Unit)
+	if !ok {
+		return fmt.Errorf("unknown unit <%s>", s.Unit)
+	}
+	if s.SourceUnit == "" {
+		return nil
+	}
+	from, ok := lookupUnit(s.SourceUnit)
+	if !ok {
+		return fmt.Errorf("unknown unit <%s>", s.SourceUnit)
+	}
+	if from.dimension != to.dimension {
+		return fmt.Errorf("source unit <%s> of <%s> cannot be converted to <%s> of <%s>", s.SourceUnit, from.dimension, s.Unit, to.dimension)
+	}
+	return nil
+}
+
+// evalUnit converts a number to the unit of s from the unit named by its
+// sibling, or else the source unit of s. numbers without a known source
+// unit are taken to be in the unit of s already
+func evalUnit(s *Schema, v any, opts EvalOptions) (any, error) {
+	n, ok := toFloat(v)
+	if !ok {
+		// values of the wrong type are left to the type check
+		return v, nil
+	}
+	source := s.SourceUnit
+	if s.UnitFrom != "" {
+		if sibling, ok := opts.Siblings[s.UnitFrom].(string); ok && sibling != "" {
+			source = sibling
+		}
+	}
+	if source == "" {
+		return v, nil
+	}
+
+	from, ok := lookupUnit(source)
+	if !ok {
+		return v, fmt.Errorf("%w, unknown unit <%s>", ErrUnit, source)
+	}
+	to, ok := lookupUnit(s.Unit)
+	if !ok {
+		return v, fmt.Errorf("%w, unknown unit <%s>", ErrUnit, s.Unit)
+	}
+	if from.dimension != to.dimension {
+		return v, fmt.Errorf("%w, unit <%s> of <%s> cannot be converted to <%s> of <%s>", ErrUnit, source, from.dimension, s.Unit, to.dimension)
+	}
+	if from.name == to.name {
+		return v, nil
+	}
+
+	converted := n * from.factor / to.factor
+	detail := fmt.Sprintf("converted from <%s> to <%s>", from.name, to.name)
+	// the type was checked before conversion, a value that must be an
+	// integer is kept one
+	if integerOnly(s.Type) {
+		converted = math.Round(converted)
+		detail += ", rounded to an integer"
+	}
+	opts.audit(AuditEntry{
+		Action: AuditUnit,
+		From:   v,
+		To:     converted,
+		Detail: detail,
+	})
+	return converted, nil
+}
+
+// integerOnly reports whether t allows integers but not other numbers
+func integerOnly(t Type) bool {
+	integer := false
+	for _, name := range t {
+		switch name {
+		case "number":
+			return false
+		case "integer":
+			integer = true
+		}
+	}
+	return integer
+}
@@ -0,0 +1,183 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnits(t *testing.T) {
+	testCases := []struct {
+		description   string
+		schema        string
+		input         any
+		siblings      map[string]any
+		expected      any
+		expectedAudit []AuditEntry
+		expectedErr   error
+	}{
+		{
+			description:   "it should convert from the unit of the sibling key",
+			schema:        `{ "type": "number", "x-unit": "sqft", "x-unit-from": "LotSizeUnits" }`,
+			input:         2.0,
+			siblings:      map[string]any{"LotSizeUnits": "Acres"},
+			expected:      87120.0,
+			expectedAudit: []AuditEntry{{Path: "/LotSize", Action: AuditUnit, From: 2.0, To: 87120.0, Detail: "converted from <acres> to <sqft>"}},
+		},
+		{
+			description:   "it should fall back to the source unit",
+			schema:        `{ "type": "number", "x-unit": "sqft", "x-unit-from": "LotSizeUnits", "x-source-unit": "sqyd" }`,
+			input:         10.0,
+			expected:      90.0,
+			expectedAudit: []AuditEntry{{Path: "/LotSize", Action: AuditUnit, From: 10.0, To: 90.0, Detail: "converted from <sqyd> to <sqft>"}},
+		},
+		{
+			description: "it should not convert values already in the unit",
+			schema:      `{ "type": "number", "x-unit": "sqft", "x-unit-from": "LotSizeUnits" }`,
+			input:       10.0,
+			siblings:    map[string]any{"LotSizeUnits": "Square Feet"},
+			expected:    10.0,
+		},
+		{
+			description: "it should check the maximum against the converted value",
+			schema:      `{ "type": "number", "x-unit": "sqft", "x-source-unit": "acres", "maximum": 50000 }`,
+			input:       2.0,
+			expectedErr: ErrRange,
+		},
+		{
+			description: "it should reject sibling units of another dimension",
+			schema:      `{ "type": "number", "x-unit": "sqft", "x-unit-from": "LotSizeUnits" }`,
+			input:       2.0,
+			siblings:    map[string]any{"LotSizeUnits": "ft"},
+			expectedErr: ErrUnit,
+		},
+		{
+			description:   "it should round converted values of integer properties",
+			schema:        `{ "type": "integer", "x-unit": "m", "x-source-unit": "ft" }`,
+			input:         10.0,
+			expected:      3.0,
+			expectedAudit: []AuditEntry{{Path: "/LotSize", Action: AuditUnit, From: 10.0, To: 3.0, Detail: "converted from <ft> to <m>, rounded to an integer"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		s, err := Load(strings.NewReader(testCase.schema))
+		if err != nil {
+			t.Fatalf("%s: failed to load schema: %s", testCase.description, err)
+		}
+		audit := []AuditEntry{}
+		out, err := s.EvalWith(testCase.input, EvalOptions{
+			Siblings: testCase.siblings,
+			Path:     "/LotSize",
+			Audit:    func(e AuditEntry) { audit = append(audit, e) },
+		})
+		if testCase.expectedErr != nil {
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("%s: expected error <%s>, got <%v>", testCase.description, testCase.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error <%s>", testCase.description, err)
+		}
+		if !reflect.DeepEqual(out, testCase.expected) {
+			t.Fatalf("%s: output <%v> does not match expected <%v>", testCase.description, out, testCase.expected)
+		}
+		if testCase.expectedAudit == nil {
+			testCase.expectedAudit = []AuditEntry{}
+		}
+		if !reflect.DeepEqual(audit, testCase.expectedAudit) {
+			t.Fatalf("%s: audit <%+v> does not match expected <%+v>", testCase.description, audit, testCase.expectedAudit)
+		}
+	}
+}
+
+func TestUnitsNested(t *testing.T) {
+	s, err := Load(strings.NewReader(`{
+		"type": "array",
+		"items": {
+			"type": "object",
+			"properties": { "Area": { "type": "number", "x-unit": "sqft", "x-unit-from": "AreaUnits" } }
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	audit := []AuditEntry{}
+	out, err := s.EvalWith([]any{map[string]any{"Area": 10.0, "AreaUnits": "sqyd"}}, EvalOptions{
+		Path:  "/Rooms",
+		Audit: func(e AuditEntry) { audit = append(audit, e) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	if !reflect.DeepEqual(out, []any{map[string]any{"Area": 90.0, "AreaUnits": "sqyd"}}) {
+		t.Fatalf("unexpected output <%v>", out)
+	}
+	if len(audit) != 1 || audit[0].Path != "/Rooms/0/Area" {
+		t.Fatalf("expected a single audit entry at </Rooms/0/Area>, got <%+v>", audit)
+	}
+}
+
+func TestLoadUnits(t *testing.T) {
+	testCases := []struct {
+		description string
+		schema      string
+		expectedErr string
+	}{
+		{
+			description: "it should reject unknown units",
+			schema:      `{ "x-unit": "furlongs" }`,
+			expectedErr: "unknown unit <furlongs>",
+		},
+		{
+			description: "it should reject source units of another dimension",
+			schema:      `{ "x-unit": "sqft", "x-source-unit": "m" }`,
+			expectedErr: "source unit <m> of <length> cannot be converted to <sqft> of <area>",
+		},
+		{
+			description: "it should require a unit to convert to",
+			schema:      `{ "x-source-unit": "acres" }`,
+			expectedErr: "require an x-unit",
+		},
+	}
+
+	for _, testCase := range testCases {
+		_, err := Load(strings.NewReader(testCase.schema))
+		if err == nil || !strings.Contains(err.Error(), testCase.expectedErr) {
+			t.Fatalf("%s: expected error containing <%s>, got <%v>", testCase.description, testCase.expectedErr, err)
+		}
+	}
+}
+
+func TestUnitsAliasedSibling(t *testing.T) {
+	s, err := Load(strings.NewReader(`{
+		"type": "object",
+		"x-key-folding": ["case", "separators"],
+		"properties": {
+			"Area": { "type": "number", "x-unit": "sqft", "x-unit-from": "AreaUnits" },
+			"AreaUnits": { "type": "string", "x-aliases": ["A_UNITS"] }
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	testCases := []struct {
+		description string
+		key         string
+	}{
+		{description: "it should find a sibling sent under an alias", key: "A_UNITS"},
+		{description: "it should find a sibling sent under a folded key", key: "area_units"},
+	}
+
+	for _, testCase := range testCases {
+		out, err := s.EvalWith(map[string]any{"Area": 10.0, testCase.key: "sqyd"}, EvalOptions{})
+		if err != nil {
+			t.Fatalf("%s: unexpected error <%s>", testCase.description, err)
+		}
+		if area := out.(map[string]any)["Area"]; area != 90.0 {
+			t.Fatalf("%s: expected the area to be converted, got <%v>", testCase.description, area)
+		}
+	}
+}
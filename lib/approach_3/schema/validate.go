@@ -0,0 +1,214 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Result is the outcome of Validate
+type Result struct {
+	Valid bool `json:"valid"`
+	// Value is the document after coercion, transforms and unit
+	// conversions. it is nil when the document is invalid
+	Value    any          `json:"value"`
+	Errors   []Issue      `json:"errors"`
+	Warnings []Issue      `json:"warnings"`
+	Audit    []AuditEntry `json:"audit,omitempty"`
+	// Annotations are the annotations of the schemas the document is
+	// valid against, by json pointer
+	Annotations map[string][]Annotation `json:"annotations,omitempty"`
+}
+
+// Issue is a single failure found by Validate
+type Issue struct {
+	Path    string `json:"path"` // json pointer to the failing value
+	Message string `json:"message"`
+	Err     error  `json:"-"`
+}
+
+// Validate validates a json document of any root type against s
+func Validate(doc []byte, s *Schema) (Result, error) {
+	return ValidateWith(doc, s, EvalOptions{})
+}
+
+// ValidateWith validates a json document like Validate, evaluating it
+// with opts. failures are split into one Issue per failing value, the
+// error is only set when doc is not json
+func ValidateWith(doc []byte, s *Schema, opts EvalOptions) (Result, error) {
+	var v any
+	if err := json.Unmarshal(doc, &v); err != nil {
+		return Result{}, fmt.Errorf("document is not valid json: %w", err)
+	}
+
+	res := Result{Errors: []Issue{}, Warnings: []Issue{}, Annotations: map[string][]Annotation{}}
+	audit := opts.Audit
+	opts.Audit = func(e AuditEntry) {
+		res.Audit = append(res.Audit, e)
+		if audit != nil {
+			audit(e)
+		}
+	}
+	annotate := opts.Annotate
+	opts.Annotate = func(a Annotation) {
+		res.Annotations[a.Path] = append(res.Annotations[a.Path], a)
+		if annotate != nil {
+			annotate(a)
+		}
+	}
+
+	out, err := s.EvalWith(v, opts)
+	switch {
+	case err == nil:
+		res.Valid, res.Value = true, out
+	case IsWarning(err):
+		res.Valid, res.Value = true, out
+		res.Errors, res.Warnings = issues(opts.Path, err)
+	default:
+		res.Errors, res.Warnings = issues(opts.Path, err)
+	}
+	return res, nil
+}
+
+// issues splits err into the failures of the values under path, the
+// ones held in a Warning are returned as warnings
+func issues(path string, err error) (errs, warns []Issue) {
+	errs, warns = []Issue{}, []Issue{}
+	add := func(e, w []Issue) {
+		errs = append(errs, e...)
+		warns = append(warns, w...)
+	}
+	switch e := err.(type) {
+	case *Warning:
+		e2, w := issues(path, e.Err)
+		return errs, append(e2, w...)
+	case *PropertiesError:
+		for _, k := range sortedKeys(e.Keys) {
+			add(issues(path+"/"+escapePointer(k), e.Keys[k]))
+		}
+		return errs, warns
+	case *ItemsError:
+		for _, i := range e.indexes() {
+			add(issues(path+"/"+strconv.Itoa(i), e.Items[i]))
+		}
+		return errs, warns
+	}
+	// failures joined with the keys or items of a value are split apart,
+	// anything else is a failure of the value itself
+	if structured(err) {
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			if errors.Is(e, ErrInvalidObject) || errors.Is(e, ErrInvalidItems) {
+				continue
+			}
+			add(issues(path, e))
+		}
+		return errs, warns
+	}
+	return append(errs, Issue{Path: path, Message: err.Error(), Err: err}), warns
+}
+
+// structured reports whether err holds the failures of keys or items
+// directly or through joined errors
+func structured(err error) bool {
+	switch e := err.(type) {
+	case *Warning:
+		return structured(e.Err)
+	case *PropertiesError, *ItemsError:
+		return true
+	case interface{ Unwrap() []error }:
+		for _, c := range e.Unwrap() {
+			if structured(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PropertiesError holds the failures of the keys of an object by key as
+// sent. Eval returns it joined with ErrInvalidObject, keeping the warnings
+// of the other keys as their Warning, or in a Warning when every failure
+// is a warning
+type PropertiesError struct {
+	Keys map[string]error
+
+	// duplicate is set when keys collided through their aliases
+	duplicate bool
+	warning   bool
+}
+
+func (e *PropertiesError) Error() string {
+	msgs := make([]string, 0, len(e.Keys))
+	for _, k := range sortedKeys(e.Keys) {
+		msgs = append(msgs, fmt.Sprintf("key <%s>: %s", k, e.Keys[k]))
+	}
+	if e.warning {
+		return strings.Join(msgs, "\n")
+	}
+	return fmt.Sprintf("\tcould not validate all key:vals in obj: [%s]", strings.Join(msgs, " "))
+}
+
+// Unwrap exposes duplicate keys only, failures of nested values are
+// classified by the object holding them. warnings expose every failure
+func (e *PropertiesError) Unwrap() []error {
+	if e.warning {
+		errs := make([]error, 0, len(e.Keys))
+		for _, k := range sortedKeys(e.Keys) {
+			errs = append(errs, e.Keys[k])
+		}
+		return errs
+	}
+	if e.duplicate {
+		return []error{ErrDuplicateKey}
+	}
+	return nil
+}
+
+// ItemsError holds the failures of the items of an array by index. Eval
+// returns it joined with ErrInvalidItems, or in a Warning when every
+// failure is a warning
+type ItemsError struct {
+	Items map[int]error
+
+	warning bool
+}
+
+func (e *ItemsError) Error() string {
+	msgs := make([]string, 0, len(e.Items))
+	for _, i := range e.indexes() {
+		if e.warning {
+			msgs = append(msgs, fmt.Sprintf("item %d: %s", i, e.Items[i]))
+		} else {
+			msgs = append(msgs, e.Items[i].Error())
+		}
+	}
+	if e.warning {
+		return strings.Join(msgs, "\n")
+	}
+	return fmt.Sprintf("\terror: could not validate all items in array: [%s]", strings.Join(msgs, " "))
+}
+
+// Unwrap exposes nothing for errors, failures of items are classified by
+// the array holding them. warnings expose every failure
+func (e *ItemsError) Unwrap() []error {
+	if !e.warning {
+		return nil
+	}
+	errs := make([]error, 0, len(e.Items))
+	for _, i := range e.indexes() {
+		errs = append(errs, e.Items[i])
+	}
+	return errs
+}
+
+func (e *ItemsError) indexes() []int {
+	idx := make([]int, 0, len(e.Items))
+	for i := range e.Items {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return idx
+}
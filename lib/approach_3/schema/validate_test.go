@@ -0,0 +1,184 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		description      string
+		schema           string
+		doc              string
+		expectedValid    bool
+		expectedValue    any
+		expectedErrors   []string
+		expectedWarnings []string
+	}{
+		{
+			description:   "it should validate a scalar root",
+			schema:        `{ "type": "string", "enum": [ "abc" ] }`,
+			doc:           `"abc"`,
+			expectedValid: true,
+			expectedValue: "abc",
+		},
+		{
+			description:    "it should report a failing scalar root at the root",
+			schema:         `{ "type": "string", "enum": [ "abc" ] }`,
+			doc:            `"abcd"`,
+			expectedErrors: []string{""},
+		},
+		{
+			description:   "it should accept anything against a schema without keywords",
+			schema:        `{}`,
+			doc:           `{ "AgentId": 1, "Offices": [ "a" ] }`,
+			expectedValid: true,
+			expectedValue: map[string]any{"AgentId": 1.0, "Offices": []any{"a"}},
+		},
+		{
+			description:   "it should accept any object against an object schema without properties",
+			schema:        `{ "type": "object" }`,
+			doc:           `{ "AgentId": 1 }`,
+			expectedValid: true,
+			expectedValue: map[string]any{"AgentId": 1.0},
+		},
+		{
+			description:    "it should report every failing key by pointer",
+			schema:         `{ "type": "object", "properties": { "Name": { "type": "string" }, "a/b": { "type": "number" } } }`,
+			doc:            `{ "Name": 1, "a/b": "x" }`,
+			expectedErrors: []string{"/Name", "/a~1b"},
+		},
+		{
+			description:    "it should report failing items of a root array by index",
+			schema:         `{ "type": "array", "items": { "type": "object", "properties": { "Url": { "type": "string" } } } }`,
+			doc:            `[ { "Url": "a" }, { "Url": 1 }, { "Url": 2 } ]`,
+			expectedErrors: []string{"/1/Url", "/2/Url"},
+		},
+		{
+			description:    "it should report missing required keys on the object",
+			schema:         `{ "type": "object", "required": [ "OfficeId" ], "properties": { "OfficeId": { "type": "string" } } }`,
+			doc:            `{ "Phone": "555" }`,
+			expectedErrors: []string{""},
+		},
+		{
+			description:      "it should report warnings by pointer and keep the value",
+			schema:           `{ "type": "object", "properties": { "Media": { "type": "array", "items": { "type": "string", "enum": [ "ab" ], "x-severity": "warning" } } } }`,
+			doc:              `{ "Media": [ "ab", "abc" ] }`,
+			expectedValid:    true,
+			expectedValue:    map[string]any{"Media": []any{"ab", "abc"}},
+			expectedWarnings: []string{"/Media/1"},
+		},
+		{
+			description:    "it should report the failing keys of a nested object",
+			schema:         `{ "type": "object", "properties": { "Office": { "type": "object", "properties": { "Phone": { "type": "string" } } } } }`,
+			doc:            `{ "Office": { "Phone": 5 } }`,
+			expectedErrors: []string{"/Office/Phone"},
+		},
+		{
+			description:      "it should keep the warnings of keys next to failing keys",
+			schema:           `{ "type": "object", "properties": { "ListPrice": { "type": "number", "maximum": 100, "x-severity": "warning" }, "Name": { "type": "string" } } }`,
+			doc:              `{ "ListPrice": 1000, "Name": 1 }`,
+			expectedErrors:   []string{"/Name"},
+			expectedWarnings: []string{"/ListPrice"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		s, err := Load(strings.NewReader(testCase.schema))
+		if err != nil {
+			t.Fatalf("%s: failed to load schema: %s", testCase.description, err)
+		}
+		res, err := Validate([]byte(testCase.doc), s)
+		if err != nil {
+			t.Fatalf("%s: unexpected error <%s>", testCase.description, err)
+		}
+		if res.Valid != testCase.expectedValid {
+			t.Fatalf("%s: expected valid <%t>, got <%t> with errors <%+v>", testCase.description, testCase.expectedValid, res.Valid, res.Errors)
+		}
+		if !reflect.DeepEqual(res.Value, testCase.expectedValue) {
+			t.Fatalf("%s: value <%v> does not match expected <%v>", testCase.description, res.Value, testCase.expectedValue)
+		}
+		if paths := issuePaths(res.Errors); !reflect.DeepEqual(paths, orEmpty(testCase.expectedErrors)) {
+			t.Fatalf("%s: error paths <%v> do not match expected <%v>", testCase.description, paths, testCase.expectedErrors)
+		}
+		if paths := issuePaths(res.Warnings); !reflect.DeepEqual(paths, orEmpty(testCase.expectedWarnings)) {
+			t.Fatalf("%s: warning paths <%v> do not match expected <%v>", testCase.description, paths, testCase.expectedWarnings)
+		}
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	// invalid json is the only error
+	if _, err := Validate([]byte(`{ "a": `), &Schema{}); err == nil {
+		t.Fatalf("expected an error for invalid json")
+	}
+
+	// a nil schema constrains nothing
+	res, err := Validate([]byte(`[ 1 ]`), nil)
+	if err != nil || !res.Valid {
+		t.Fatalf("expected a nil schema to accept the document, got <%+v> <%v>", res, err)
+	}
+
+	// issues keep the error they were split from
+	s, err := Load(strings.NewReader(`{ "type": "object", "properties": { "Age": { "type": "number", "maximum": 10 } } }`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	res, err = Validate([]byte(`{ "Age": 11 }`), s)
+	if err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	if len(res.Errors) != 1 || !errors.Is(res.Errors[0].Err, ErrRange) {
+		t.Fatalf("expected a single range error, got <%+v>", res.Errors)
+	}
+}
+
+func TestValidateWithAudit(t *testing.T) {
+	s, err := Load(strings.NewReader(`{
+		"type": "object",
+		"properties": { "Area": { "type": "number", "x-unit": "sqft", "x-unit-from": "AreaUnits" } }
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	res, err := ValidateWith([]byte(`{ "Area": 10, "AreaUnits": "sqyd" }`), s, EvalOptions{Coerce: true})
+	if err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	if !res.Valid || len(res.Audit) != 1 || res.Audit[0].Path != "/Area" {
+		t.Fatalf("expected a single audit entry at </Area>, got <%+v>", res)
+	}
+}
+
+func FuzzValidate(f *testing.F) {
+	f.Add(`{ "type": "object", "properties": { "a": { "type": "integer" } } }`, `{ "a": 1 }`)
+	f.Add(`{ "type": "array", "items": { "anyOf": [ { "type": "string" }, {} ] } }`, `[ "a", 1, null ]`)
+	f.Add(`{ "required": [ "a" ], "patternProperties": { "^x": { "enum": [ 1 ] } } }`, `{ "xy": 2 }`)
+	f.Add(`{ "properties": { "a": null }, "additionalProperties": false }`, `{ "a": 1, "b": 2 }`)
+	f.Add(`{}`, `"a"`)
+	f.Fuzz(func(t *testing.T, schema, doc string) {
+		s, err := Load(strings.NewReader(schema))
+		if err != nil {
+			return
+		}
+		// any schema that loads must validate any document without panicking
+		_, _ = Validate([]byte(doc), s)
+	})
+}
+
+func issuePaths(issues []Issue) []string {
+	paths := []string{}
+	for _, i := range issues {
+		paths = append(paths, i.Path)
+	}
+	return paths
+}
+
+func orEmpty(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/report"
+	"cmenke/go-playground/lib/approach_3/store"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxBodySize is the largest request body accepted, in bytes
+const MaxBodySize = 32 << 20
+
+// content type of batch requests and responses, one json value per line
+const ndjson = "application/x-ndjson"
+
+// Result is the response to validating a single listing
+type Result struct {
+	Listing approach_3.Listing `json:"listing"`
+	// Errors holds every reported key:value, warnings included
+	Errors []report.BadKeyVal `json:"errors"`
+	Valid  bool               `json:"valid"`
+}
+
+// Server exposes a schema store over http:
+//
+//	POST /validate        validate a listing, or an ndjson batch of listings
+//	GET  /schemas/{mls}   the effective schema of mls
+//	POST /schemas/{mls}   upload the overlay of mls
+//	GET  /healthz         health and current schema version
+type Server struct {
+	store  *store.Store
+	coerce bool
+	mux    *http.ServeMux
+}
+
+// New returns a Server validating against st, coercing values by default
+// when coerce is set
+func New(st *store.Store, coerce bool) *Server {
+	s := &Server{store: st, coerce: coerce, mux: http.NewServeMux()}
+	s.mux.HandleFunc("POST /validate", s.handleValidate)
+	s.mux.HandleFunc("GET /schemas/{mls}", s.handleGetSchema)
+	s.mux.HandleFunc("POST /schemas/{mls}", s.handlePutSchema)
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleValidate validates the listing of the body. with an ndjson content
+// type the body is a batch and a result is streamed back per listing
+func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	coerce := s.coerce
+	if v := r.URL.Query().Get("coerce"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid coerce <%s>, expected a boolean", v))
+			return
+		}
+		coerce = parsed
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodySize))
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), ndjson) {
+		var l approach_3.Listing
+		if err := dec.Decode(&l); err != nil {
+			writeError(w, decodeStatus(err), fmt.Errorf("failed to decode listing: %w", err))
+			return
+		}
+		writeJSON(w, http.StatusOK, s.validate(l, coerce))
+		return
+	}
+
+	// decode the whole batch first so a malformed line fails the request
+	// before any result is written
+	listings := []approach_3.Listing{}
+	for {
+		var l approach_3.Listing
+		err := dec.Decode(&l)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeError(w, decodeStatus(err), fmt.Errorf("failed to decode listing %d: %w", len(listings)+1, err))
+			return
+		}
+		listings = append(listings, l)
+	}
+
+	w.Header().Set("Content-Type", ndjson)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, l := range listings {
+		if err := enc.Encode(s.validate(l, coerce)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) validate(l approach_3.Listing, coerce bool) Result {
+	res := Result{Errors: []report.BadKeyVal{}}
+	err := s.store.Validate(&l, approach_3.ReporterFunc(func(e report.BadKeyVal) error {
+		res.Errors = append(res.Errors, e)
+		return nil
+	}), coerce)
+	res.Listing = l
+	res.Valid = err == nil
+	return res
+}
+
+func (s *Server) handleGetSchema(w http.ResponseWriter, r *http.Request) {
+	v := s.store.Current()
+	effective, err := v.Resolver.For(r.PathValue("mls"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("X-Schema-Version", v.ID)
+	writeJSON(w, http.StatusOK, effective)
+}
+
+func (s *Server) handlePutSchema(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+	if err != nil {
+		writeError(w, decodeStatus(err), fmt.Errorf("failed to read overlay: %w", err))
+		return
+	}
+	mls := r.PathValue("mls")
+	v, err := s.store.PutOverlay(mls, data)
+	if errors.Is(err, store.ErrInvalidOverlay) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	effective, err := v.Resolver.For(mls)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("X-Schema-Version", v.ID)
+	writeJSON(w, http.StatusOK, effective)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	v := s.store.Current()
+	writeJSON(w, http.StatusOK, struct {
+		Status        string    `json:"status"`
+		SchemaVersion string    `json:"schema_version"`
+		LoadedAt      time.Time `json:"loaded_at"`
+	}{"ok", v.ID, v.LoadedAt})
+}
+
+// decodeStatus tells a body over MaxBodySize apart from a malformed one
+func decodeStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"bufio"
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/store"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	dir := t.TempDir()
+	base := filepath.Join(dir, "schema.json")
+	err := os.WriteFile(base, []byte(`{ "properties": { "ListPrice": { "type": "number" }, "Remarks": { "type": "string" } } }`), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write schema: %s", err)
+	}
+	st, err := store.New(base, dir)
+	if err != nil {
+		t.Fatalf("failed to create store: %s", err)
+	}
+	srv := httptest.NewServer(New(st, true))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestValidate(t *testing.T) {
+	srv := newTestServer(t)
+
+	testCases := []struct {
+		description    string
+		contentType    string
+		query          string
+		body           string
+		expectedStatus int
+		expected       []bool // validity of each result
+	}{
+		{
+			description:    "it should return the cleaned listing with its errors",
+			body:           `{"mls": "a", "docid": "1", "data": [{"key": "ListPrice", "value": "100"}, {"key": "Remarks", "value": 1}]}`,
+			expectedStatus: http.StatusOK,
+			expected:       []bool{false},
+		},
+		{
+			description:    "it should not coerce when asked not to",
+			query:          "?coerce=false",
+			body:           `{"mls": "a", "docid": "1", "data": [{"key": "ListPrice", "value": "100"}]}`,
+			expectedStatus: http.StatusOK,
+			expected:       []bool{false},
+		},
+		{
+			description:    "it should validate an ndjson batch",
+			contentType:    ndjson,
+			body:           "{\"mls\": \"a\", \"docid\": \"1\", \"data\": [{\"key\": \"ListPrice\", \"value\": 1}]}\n{\"mls\": \"a\", \"docid\": \"2\", \"data\": [{\"key\": \"ListPrice\", \"value\": \"x\"}]}\n",
+			expectedStatus: http.StatusOK,
+			expected:       []bool{true, false},
+		},
+		{
+			description:    "it should reject malformed listings",
+			body:           `{"mls": `,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:    "it should reject an invalid coerce parameter",
+			query:          "?coerce=maybe",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		contentType := testCase.contentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		resp, err := http.Post(srv.URL+"/validate"+testCase.query, contentType, strings.NewReader(testCase.body))
+		if err != nil {
+			t.Fatalf("%s: request failed: %s", testCase.description, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != testCase.expectedStatus {
+			t.Fatalf("%s: expected status %d, got %d", testCase.description, testCase.expectedStatus, resp.StatusCode)
+		}
+		if testCase.expected == nil {
+			continue
+		}
+
+		// errors are left raw as BadKeyVal only marshals
+		type result struct {
+			Listing approach_3.Listing `json:"listing"`
+			Errors  []json.RawMessage  `json:"errors"`
+			Valid   bool               `json:"valid"`
+		}
+		results := []result{}
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			var res result
+			if err := json.Unmarshal(scanner.Bytes(), &res); err != nil {
+				t.Fatalf("%s: failed to decode result: %s", testCase.description, err)
+			}
+			results = append(results, res)
+		}
+		if len(results) != len(testCase.expected) {
+			t.Fatalf("%s: expected %d results, got %d", testCase.description, len(testCase.expected), len(results))
+		}
+		for i, res := range results {
+			if res.Valid != testCase.expected[i] {
+				t.Fatalf("%s: expected result %d valid <%v>, got <%+v>", testCase.description, i, testCase.expected[i], res)
+			}
+			if res.Listing.SchemaVersion == "" {
+				t.Fatalf("%s: expected result %d to be stamped with the schema version", testCase.description, i)
+			}
+		}
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	srv := newTestServer(t)
+	resp, err := http.Post(srv.URL+"/validate", "application/json", strings.NewReader(`{"mls": "a", "docid": "1", "data": [{"key": "ListPrice", "value": "100"}, {"key": "Remarks", "value": 1}]}`))
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Listing struct {
+			Data []struct {
+				Key   string `json:"key"`
+				Value any    `json:"value"`
+			} `json:"data"`
+		} `json:"listing"`
+		Errors []map[string]any `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatalf("failed to decode response: %s", err)
+	}
+	if len(res.Listing.Data) != 1 || res.Listing.Data[0].Value != 100.0 {
+		t.Fatalf("expected the coerced ListPrice to be kept, got <%+v>", res.Listing.Data)
+	}
+	if len(res.Errors) != 1 || res.Errors[0]["key"] != "Remarks" || res.Errors[0]["kind"] != "type_mismatch" {
+		t.Fatalf("expected a structured error for Remarks, got <%+v>", res.Errors)
+	}
+}
+
+func TestSchemas(t *testing.T) {
+	srv := newTestServer(t)
+
+	resp, err := http.Post(srv.URL+"/schemas/a", "application/json", strings.NewReader(`{ "properties": { "Remarks": null, "Pool": { "type": "boolean" } } }`))
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected overlay upload to succeed, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(srv.URL + "/schemas/a")
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	defer resp.Body.Close()
+	var effective struct {
+		Properties map[string]any `json:"properties"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&effective); err != nil {
+		t.Fatalf("failed to decode schema: %s", err)
+	}
+	if _, ok := effective.Properties["Pool"]; !ok || effective.Properties["Remarks"] != nil || resp.Header.Get("X-Schema-Version") == "" {
+		t.Fatalf("expected the effective schema of a with its version, got <%+v>", effective)
+	}
+
+	for _, testCase := range []struct {
+		description string
+		path        string
+		body        string
+		expected    int
+	}{
+		{"it should reject overlays producing an invalid schema", "/schemas/a", `{ "properties": { "Pool": { "type": "bool" } } }`, http.StatusBadRequest},
+		{"it should reject malformed overlays", "/schemas/a", `{`, http.StatusBadRequest},
+		{"it should reject overlays recursing without consuming input", "/schemas/a", `{ "anyOf": [ { "$ref": "#" } ] }`, http.StatusBadRequest},
+		{"it should reject mls unusable as a file name", "/schemas/.hidden", `{}`, http.StatusBadRequest},
+	} {
+		resp, err := http.Post(srv.URL+testCase.path, "application/json", strings.NewReader(testCase.body))
+		if err != nil {
+			t.Fatalf("%s: request failed: %s", testCase.description, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != testCase.expected {
+			t.Fatalf("%s: expected status %d, got %d", testCase.description, testCase.expected, resp.StatusCode)
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	srv := newTestServer(t)
+	resp, err := http.Get(srv.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	defer resp.Body.Close()
+	var health map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		t.Fatalf("failed to decode health: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK || health["status"] != "ok" || health["schema_version"] == "" {
+		t.Fatalf("unexpected health <%d, %v>", resp.StatusCode, health)
+	}
+}
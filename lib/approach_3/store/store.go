@@ -0,0 +1,239 @@
+package store
+
+import (
+	"bytes"
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/overlay"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInvalidOverlay is returned by PutOverlay for overlays that cannot be
+// merged into the base schema
+var ErrInvalidOverlay = errors.New("invalid overlay")
+
+// Version is a compiled set of schema files, the base schema along with
+// its per-mls overlays
+type Version struct {
+	// ID is a hash of the content of every file, so unchanged files keep
+	// their version across reloads and restarts
+	ID       string
+	Resolver *overlay.Resolver
+	LoadedAt time.Time
+}
+
+// Store serves the latest valid Version of a base schema file and a
+// directory of <mls>.json overlays, reloading them when they change
+type Store struct {
+	basePath    string
+	overlaysDir string
+
+	current atomic.Pointer[Version]
+	// mu serializes reloads, readers only go through current
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// New loads the schema at basePath and the overlays of overlaysDir, which
+// can be empty. it fails if the initial version cannot be loaded
+func New(basePath, overlaysDir string) (*Store, error) {
+	st := &Store{
+		basePath:    basePath,
+		overlaysDir: overlaysDir,
+		now:         time.Now,
+	}
+	if _, err := st.Reload(); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// Current returns the version currently in use
+func (st *Store) Current() *Version {
+	return st.current.Load()
+}
+
+// Validate validates l against the effective schema of its mls in the
+// current version, stamping l and every report with the version id
+func (st *Store) Validate(l *approach_3.Listing, r approach_3.Reporter, coerce bool) error {
+	return st.Current().Resolver.Validate(l, r, coerce)
+}
+
+// Reload reads the schema files and swaps them in if their content changed
+// and they load, reporting whether a new version is in use. on failure
+// the current version is kept
+func (st *Store) Reload() (bool, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.reload()
+}
+
+func (st *Store) reload() (bool, error) {
+	base, overlays, err := st.read()
+	if err != nil {
+		return false, err
+	}
+	id := hash(base, overlays)
+	if cur := st.current.Load(); cur != nil && cur.ID == id {
+		return false, nil
+	}
+
+	s, err := schema.Load(bytes.NewReader(base))
+	if err != nil {
+		return false, fmt.Errorf("failed to load schema file <%s>, keeping the current version: %w", st.basePath, err)
+	}
+	s.Version = id
+	resolver := overlay.NewResolver(s, overlays)
+	// overlays are merged lazily by the resolver, resolve them now so a
+	// broken overlay does not replace a working version
+	for mls := range overlays {
+		if _, err := resolver.For(mls); err != nil {
+			return false, fmt.Errorf("%w, keeping the current version", err)
+		}
+	}
+
+	st.current.Store(&Version{ID: id, Resolver: resolver, LoadedAt: st.now()})
+	return true, nil
+}
+
+// PutOverlay validates data as the overlay of mls against the base schema
+// file, writes it to the overlays directory and reloads the store.
+// overlays that fail to merge are rejected with ErrInvalidOverlay
+func (st *Store) PutOverlay(mls string, data []byte) (*Version, error) {
+	if st.overlaysDir == "" {
+		return nil, errors.New("store has no overlays directory to write to")
+	}
+	if mls == "" || strings.HasPrefix(mls, ".") || filepath.Base(mls) != mls {
+		return nil, fmt.Errorf("%w: mls <%s> cannot be used as a file name", ErrInvalidOverlay, mls)
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	// the overlay is checked against the base schema file reload merges
+	// it into, the one in use can be older than the file
+	base, err := os.ReadFile(st.basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file <%s>: %w", st.basePath, err)
+	}
+	s, err := schema.Load(bytes.NewReader(base))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema file <%s>: %w", st.basePath, err)
+	}
+	if _, err := overlay.Merge(s, data); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOverlay, err)
+	}
+
+	// the previous overlay is put back if the store fails to reload with
+	// the new one, so it does not keep failing every later reload
+	path := filepath.Join(st.overlaysDir, mls+".json")
+	prev, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read overlay <%s>: %w", path, err)
+	}
+	existed := err == nil
+	if err := writeFile(path, data); err != nil {
+		return nil, fmt.Errorf("failed to write overlay <%s>: %w", path, err)
+	}
+
+	if _, err := st.reload(); err != nil {
+		restoreErr := os.Remove(path)
+		if existed {
+			restoreErr = writeFile(path, prev)
+		}
+		if restoreErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to restore overlay <%s>: %w", path, restoreErr))
+		}
+		return nil, err
+	}
+	return st.current.Load(), nil
+}
+
+// writeFile writes data to path through a temporary file, so a concurrent
+// reload never reads a partial file
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Watch polls the schema files every interval until ctx is done, calling
+// notify, if set, after every reload that swapped in a version or whose
+// error differs from the previous one, so files that stay broken are
+// notified once and fixing them is notified with a nil error
+func (st *Store) Watch(ctx context.Context, interval time.Duration, notify func(v *Version, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastErr := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			swapped, err := st.Reload()
+			msg := ""
+			if err != nil {
+				msg = err.Error()
+			}
+			if notify != nil && (swapped || msg != lastErr) {
+				notify(st.Current(), err)
+			}
+			lastErr = msg
+		}
+	}
+}
+
+func (st *Store) read() ([]byte, map[string][]byte, error) {
+	base, err := os.ReadFile(st.basePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read schema file <%s>: %w", st.basePath, err)
+	}
+	overlays := map[string][]byte{}
+	if st.overlaysDir != "" {
+		if overlays, err = overlay.LoadDir(st.overlaysDir); err != nil {
+			return nil, nil, err
+		}
+	}
+	return base, overlays, nil
+}
+
+// hash returns the sha256 of the base schema and overlays, in a stable
+// order, shortened to 12 hex characters
+func hash(base []byte, overlays map[string][]byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d:", len(base))
+	h.Write(base)
+
+	names := make([]string, 0, len(overlays))
+	for mls := range overlays {
+		names = append(names, mls)
+	}
+	sort.Strings(names)
+	for _, mls := range names {
+		fmt.Fprintf(h, "%d:%s%d:", len(mls), mls, len(overlays[mls]))
+		h.Write(overlays[mls])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
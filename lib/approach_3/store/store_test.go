@@ -0,0 +1,224 @@
+package store
+
+import (
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/report"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func write(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("failed to write <%s>: %s", path, err)
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "schema.json")
+	overlays := filepath.Join(dir, "overlays")
+	if err := os.Mkdir(overlays, 0o755); err != nil {
+		t.Fatalf("failed to create overlays dir: %s", err)
+	}
+	write(t, base, `{ "properties": { "ListPrice": { "type": "number" } } }`)
+
+	st, err := New(base, overlays)
+	if err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	v1 := st.Current()
+
+	// every result is stamped with the version that produced it
+	var bad report.BadKeyVal
+	rep := approach_3.ReporterFunc(func(e report.BadKeyVal) error {
+		bad = e
+		return nil
+	})
+	l := approach_3.Listing{Mls: "a", Data: []approach_3.KeyVal{{Key: "ListPrice", Value: "x"}}}
+	_ = st.Validate(&l, rep, false)
+	if l.SchemaVersion != v1.ID || bad.SchemaVersion != v1.ID {
+		t.Fatalf("expected listing and report to be stamped with <%s>, got <%s> and <%s>", v1.ID, l.SchemaVersion, bad.SchemaVersion)
+	}
+
+	if swapped, err := st.Reload(); swapped || err != nil {
+		t.Fatalf("expected unchanged files not to swap versions, got <%v, %v>", swapped, err)
+	}
+
+	// an invalid schema keeps the current version
+	write(t, base, `{ "properties": { "ListPrice": { "type": "int" } } }`)
+	if swapped, err := st.Reload(); swapped || err == nil {
+		t.Fatalf("expected invalid schema to fail reloading, got <%v, %v>", swapped, err)
+	}
+	if st.Current() != v1 {
+		t.Fatalf("expected the current version to be kept")
+	}
+
+	// and so does a broken overlay
+	write(t, base, `{ "properties": { "ListPrice": { "type": ["number", "string"] } } }`)
+	write(t, filepath.Join(overlays, "b.json"), `{ "properties": { "ListPrice": { "maximum": "1" } } }`)
+	if swapped, err := st.Reload(); swapped || err == nil {
+		t.Fatalf("expected broken overlay to fail reloading, got <%v, %v>", swapped, err)
+	}
+
+	write(t, filepath.Join(overlays, "b.json"), `{ "properties": { "ListPrice": { "type": "number" } } }`)
+	if swapped, err := st.Reload(); !swapped || err != nil {
+		t.Fatalf("expected valid files to swap versions, got <%v, %v>", swapped, err)
+	}
+	v2 := st.Current()
+	if v2.ID == v1.ID {
+		t.Fatalf("expected a new version id, got <%s>", v2.ID)
+	}
+
+	// the mls picks the overlay, both stamped with the same version
+	for _, testCase := range []struct {
+		mls      string
+		expected int
+	}{{"a", 1}, {"b", 0}} {
+		l := approach_3.Listing{Mls: testCase.mls, Data: []approach_3.KeyVal{{Key: "ListPrice", Value: "x"}}}
+		_ = st.Validate(&l, rep, false)
+		if len(l.Data) != testCase.expected || l.SchemaVersion != v2.ID {
+			t.Fatalf("mls <%s>: expected %d key:vals stamped with <%s>, got <%+v>", testCase.mls, testCase.expected, v2.ID, l)
+		}
+	}
+}
+
+func TestStoreWatch(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "schema.json")
+	write(t, base, `{ "properties": { "ListPrice": { "type": "number" } } }`)
+	st, err := New(base, "")
+	if err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	v1 := st.Current()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan *Version, 1)
+	go st.Watch(ctx, time.Millisecond, func(v *Version, err error) {
+		if err == nil {
+			reloaded <- v
+		}
+	})
+
+	write(t, base, `{ "properties": { "ListPrice": { "type": "string" } } }`)
+	select {
+	case v := <-reloaded:
+		if v.ID == v1.ID || st.Current() != v {
+			t.Fatalf("expected the watched change to swap in a new version")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the schema to reload")
+	}
+}
+
+func TestStoreWatchNotifiesChanges(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "schema.json")
+	write(t, base, `{ "properties": { "ListPrice": { "type": "number" } } }`)
+	st, err := New(base, "")
+	if err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notified := make(chan error, 100)
+	go st.Watch(ctx, time.Millisecond, func(v *Version, err error) {
+		notified <- err
+	})
+	next := func() error {
+		t.Helper()
+		select {
+		case err := <-notified:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for a notification")
+		}
+		return nil
+	}
+
+	// files that stay broken are notified once
+	if err := writeFile(base, []byte(`{ "properties": { "ListPrice": { "type": "int" } } }`)); err != nil {
+		t.Fatalf("failed to write <%s>: %s", base, err)
+	}
+	if err := next(); err == nil {
+		t.Fatalf("expected the broken schema to be notified with an error")
+	}
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case err := <-notified:
+		t.Fatalf("expected the broken schema to be notified once, got <%v>", err)
+	default:
+	}
+
+	// and fixing them back is notified without error
+	if err := writeFile(base, []byte(`{ "properties": { "ListPrice": { "type": "number" } } }`)); err != nil {
+		t.Fatalf("failed to write <%s>: %s", base, err)
+	}
+	if err := next(); err != nil {
+		t.Fatalf("expected the fixed schema to be notified without error, got <%s>", err)
+	}
+}
+
+func TestPutOverlayValidatesAgainstBaseFile(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "schema.json")
+	write(t, base, `{ "properties": { "ListPrice": { "type": "number" } } }`)
+	st, err := New(base, dir)
+	if err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+
+	// the base is edited but not reloaded yet, the overlay removes the
+	// $defs its ref needs
+	write(t, base, `{ "properties": { "ListPrice": { "$ref": "#/$defs/price" } }, "$defs": { "price": { "type": "number" } } }`)
+	if _, err := st.PutOverlay("a", []byte(`{ "$defs": null }`)); !errors.Is(err, ErrInvalidOverlay) {
+		t.Fatalf("expected the overlay to be invalid against the base file, got <%v>", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected the invalid overlay not to be written, got <%v>", err)
+	}
+}
+
+func TestPutOverlayRestoresOnFailedReload(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "schema.json")
+	write(t, base, `{ "properties": { "ListPrice": { "type": "number" } } }`)
+	st, err := New(base, dir)
+	if err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+	if _, err := st.PutOverlay("a", []byte(`{ "properties": { "Pool": { "type": "boolean" } } }`)); err != nil {
+		t.Fatalf("unexpected error <%s>", err)
+	}
+
+	// a broken overlay written behind the store's back fails the reload
+	// of every upload
+	write(t, filepath.Join(dir, "b.json"), `{ "properties": { "ListPrice": { "maximum": "1" } } }`)
+	for _, mls := range []string{"a", "c"} {
+		if _, err := st.PutOverlay(mls, []byte(`{ "properties": { "Pool": { "type": "string" } } }`)); err == nil {
+			t.Fatalf("mls <%s>: expected the upload to fail reloading", mls)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "a.json"))
+	if err != nil || string(data) != `{ "properties": { "Pool": { "type": "boolean" } } }` {
+		t.Fatalf("expected the previous overlay of a to be restored, got <%s> and error <%v>", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected the new overlay of c to be removed, got <%v>", err)
+	}
+}
+
+func TestNewFailsOnInvalidSchema(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "schema.json")
+	write(t, base, `{ "type": 1 }`)
+	if _, err := New(base, ""); err == nil {
+		t.Fatalf("expected an invalid initial schema to fail")
+	}
+}
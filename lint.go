@@ -0,0 +1,73 @@
+package main
+
+import (
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func runLint(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: jsonschema lint [flags] schema.json...")
+		fmt.Fprintln(stderr, "\nreports common authoring mistakes, exiting with 1 when any is found")
+		fs.PrintDefaults()
+	}
+	format := fs.String("format", "text", "output format, json or text")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitError
+	}
+	if *format != "json" && *format != "text" {
+		fmt.Fprintf(stderr, "unknown format <%s>, expected json or text\n", *format)
+		return exitError
+	}
+
+	paths, err := expandInputs(fs.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	enc := json.NewEncoder(stdout)
+	found := false
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to read schema file <%s>: %s\n", path, err)
+			return exitError
+		}
+		findings, err := schema.Lint(data)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", path, err)
+			return exitError
+		}
+		for _, f := range findings {
+			found = true
+			if *format == "json" {
+				err = enc.Encode(struct {
+					File string `json:"file"`
+					schema.Finding
+				}{path, f})
+			} else {
+				_, err = fmt.Fprintf(stdout, "%s:%s\n", path, f)
+			}
+			if err != nil {
+				fmt.Fprintln(stderr, err)
+				return exitError
+			}
+		}
+	}
+
+	if found {
+		return exitInvalid
+	}
+	return exitOK
+}
@@ -1,168 +1,62 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+)
 
-	"cmenke/go-playground/lib/approach_1"
-	"cmenke/go-playground/lib/approach_3"
-	"cmenke/go-playground/lib/approach_3/report"
-	"cmenke/go-playground/lib/approach_3/schema"
-	// "cmenke/go-playground/lib/approach_2"
-	// "cmenke/go-playground/lib/tracker"
+// exit codes returned by the cli
+const (
+	exitOK      = 0
+	exitInvalid = 1 // at least one key:value was rejected
+	exitError   = 2 // bad usage, unreadable input or schema
 )
 
-func main() {
-	testData := []byte(`{
-        "mls": "rets-properties-test",
-        "docid": "1234",
-        "data": [
-            {
-                "key": "ListPrice",
-                "value": "100000"
-            },
-            {
-                "key": "ListPrice",
-                "value": 100000.10
-            },
-            {
-                "key": "ListPrice",
-                "value": 100000
-            },
-            {
-                "key": "Appliances",
-                "value": "[\"a\", \"b\", \"c\"]"
-            },
-            {
-                "key": "Appliances",
-                "value": ["a", 10, "c"]
-            },
-            {
-                "key": "Appliances",
-                "value": ["a", "b", "c"]
-            },
-            {
-                "key": "NumArray",
-                "value": "[1, 2, 3]"
-            },
-            {
-                "key": "NumArray",
-                "value": [1, "2", 3]
-            },
-            {
-                "key": "NumArray",
-                "value": [1, 2, 3]
-            },
-            {
-                "key": "DontMapMe",
-                "value": "555-555-5555"
-            },
-            {
-                "key": "Geo",
-                "value": [
-					{
-						"Timezone": "im a string now, screw you",
-						"identifier": "GeoNSRF"
-					}
-				]	
-            },
-            {
-                "key": "Geo",
-                "value": [
-					{
-						"Timezone": {
-							"TimezoneCode": "P",
-							"TimezoneStdOffset": "-8",
-							"Name": "America/Los_Angeles",
-							"ObservesDLS": "true"
-						},
-						"identifier": "GeoNSRF"
-					}
-				]	
-            },
-            {
-                "key": "Geo",
-                "value": [
-					{
-						"Timezone": {
-							"TimezoneCode": "P",
-							"TimezoneStdOffset": "-8",
-							"Name": "America/Los_Angeles",
-							"ObservesDLS": true
-						},
-						"identifier": "GeoNSRF"
-					}
-				]	
-            }
-        ]
-    }`)
+const usage = `usage: jsonschema <command> [flags] [args]
 
-	fmt.Println("------Approach One--------")
-	var l approach_1.Listing
-	err := json.Unmarshal(testData, &l)
-	if err != nil {
-		fmt.Println("approach_1: error unmarshaling test listing")
-		panic(err)
-	}
-	fmt.Printf("before clean: %+v\n", l)
-	validFields := l.ValidFields()
-	if len(validFields) > 0 {
-		l.Data = validFields
-	}
-	fmt.Printf("after clean: %+v\n", l)
-	_ = approach_1.TypeTracker.Output()
+commands:
+  validate   validate listing files against a schema
+  infer      propose a schema from sample listing files
+  diff       classify the changes between two schemas
+  lint       report common authoring mistakes in schemas
+  resolve    print the effective schema of an mls with its overlay
+  serve      serve validation and schemas over http
+  gen        generate typed Go structs from a schema
 
-	// fmt.Println("\n------Approach two--------")
-	// var l2 approach_2.Listing
-	// err = json.Unmarshal(testData, &l2)
-	// if err != nil {
-	// 	panic(err)
-	// }
+run 'jsonschema <command> -h' for the flags of a command
+`
 
-	// fmt.Printf("before clean: %+v\n", l2)
-	// typeTracker := tracker.StdOutTracker{}
-	// _ = l2.ValidateTypes(&typeTracker)
-	// fmt.Printf("after clean: %+v\n", l2)
-	// _ = typeTracker.Output()
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
 
-	fmt.Println("\n------Approach three--------")
-	// read in json string listing
-	var l3 approach_3.Listing
-	err = json.Unmarshal(testData, &l3)
-	if err != nil {
-		fmt.Println("approach_3: error unmarshaling test listing")
-		panic(err)
-	}
-	// read in schema
-	var mapping schema.Schema
-	file, err := os.ReadFile("./schema.json")
-	if err != nil {
-		fmt.Println("approach_3: error reading schema file")
-		panic(err)
-	}
-	err = json.Unmarshal(file, &mapping)
-	if err != nil {
-		fmt.Println("approach_3: error unmarshaling schema")
-		panic(err)
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitError
 	}
 
-	// debug print listing before validation
-	str, _ := json.MarshalIndent(l3, "", "\t") 
-	fmt.Printf("before clean:\n%s\n", str)
-
-	// loop over each key:value, validating each one
-	// if key:value is "invalid", remove it
-	newData := make([]approach_3.KeyVal, 0, len(l3.Data))
-	for _, e := range l3.Data {
-		validatedKeyVal, err := e.Validate(&mapping, approach_3.ReporterFunc(report.StdOutReporter), true)
-		if err == nil {
-            newData = append(newData, validatedKeyVal)
-		}
+	switch args[0] {
+	case "validate":
+		return runValidate(args[1:], stdin, stdout, stderr)
+	case "infer":
+		return runInfer(args[1:], stdin, stdout, stderr)
+	case "diff":
+		return runDiff(args[1:], stdin, stdout, stderr)
+	case "lint":
+		return runLint(args[1:], stdout, stderr)
+	case "resolve":
+		return runResolve(args[1:], stdout, stderr)
+	case "serve":
+		return runServe(args[1:], stderr)
+	case "gen":
+		return runGen(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "unknown command <%s>\n\n%s", args[0], usage)
+		return exitError
 	}
-
-	// assign validated data
-	l3.Data = newData
-	str, _ = json.MarshalIndent(l3, "", "\t") 
-	fmt.Printf("\nafter clean:\n%s\n", str)
 }
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateCommand(t *testing.T) {
+	testCases := []struct {
+		description      string
+		args             []string
+		stdin            string
+		expectedCode     int
+		expectedListings int
+		expectedErrors   []string
+		expectedOutput   []string
+	}{
+		{
+			description:      "it should clean every listing and report rejected key:vals",
+			args:             []string{"validate", "--schema", "schema.json", "--coerce", "--format", "json", "testdata/listings.ndjson"},
+			expectedCode:     exitInvalid,
+			expectedListings: 2,
+			expectedErrors:   []string{`"key":"Appliances"`, `"severity":"warning"`},
+		},
+		{
+			description:      "it should stop at the first invalid listing when failing fast",
+			args:             []string{"validate", "--schema", "schema.json", "--fail-fast", "testdata/*.ndjson"},
+			expectedCode:     exitInvalid,
+			expectedListings: 1,
+			expectedErrors:   []string{"error: mls <rets-properties-test> docid <1234> key <ListPrice>"},
+			expectedOutput:   []string{`"docid":"1234"`},
+		},
+		{
+			description:      "it should read listings from stdin",
+			args:             []string{"validate", "--schema", "schema.json"},
+			stdin:            `{"mls": "a", "docid": "1", "data": [{"key": "ListPrice", "value": 1}]}`,
+			expectedCode:     exitOK,
+			expectedListings: 1,
+		},
+		{
+			description:      "it should apply the overlay of the listing mls",
+			args:             []string{"validate", "--schema", "schema.json", "--overlays", "testdata/overlays"},
+			stdin:            `{"mls": "rets-properties-test", "docid": "1", "data": [{"key": "ListPrice", "value": -1}]} {"mls": "other", "docid": "2", "data": [{"key": "ListPrice", "value": -1}]}`,
+			expectedCode:     exitInvalid,
+			expectedListings: 2,
+			expectedErrors:   []string{"error: mls <rets-properties-test> docid <1> key <ListPrice>"},
+		},
+		{
+			description:      "it should quarantine unmapped keys to extras",
+			args:             []string{"validate", "--schema", "schema.json", "--unmapped", "quarantine", "--format", "json"},
+			stdin:            `{"mls": "a", "docid": "1", "data": [{"key": "ListPrice", "value": 1}, {"key": "DontMapMe", "value": "555-555-5555"}]}`,
+			expectedCode:     exitOK,
+			expectedListings: 1,
+			expectedErrors:   []string{`"key":"DontMapMe"`, `"event":"unmapped"`},
+			expectedOutput:   []string{`"extras":[{"key":"DontMapMe","value":"555-555-5555"}]`},
+		},
+		{
+			description:      "it should reject listings with unmapped keys",
+			args:             []string{"validate", "--schema", "schema.json", "--unmapped", "reject"},
+			stdin:            `{"mls": "a", "docid": "1", "data": [{"key": "ListPrice", "value": 1}, {"key": "DontMapMe", "value": "555-555-5555"}]}`,
+			expectedCode:     exitInvalid,
+			expectedListings: 0,
+			expectedErrors:   []string{"error: mls <a> docid <1> key <DontMapMe>", "error: mls <a> docid <1> key <ListPrice>"},
+		},
+		{
+			description:      "it should validate listings as flat documents",
+			args:             []string{"validate", "--schema", "schema.json", "--document", "--duplicates", "last", "--format", "json"},
+			stdin:            `{"mls": "a", "docid": "1", "data": [{"key": "ListPrice", "value": "x"}, {"key": "ListPrice", "value": 1}]}`,
+			expectedCode:     exitOK,
+			expectedListings: 1,
+			expectedOutput:   []string{`"data":[{"key":"ListPrice","value":1}]`},
+		},
+		{
+			description:      "it should apply the unmapped policy to flat documents",
+			args:             []string{"validate", "--schema", "schema.json", "--document", "--unmapped", "quarantine", "--format", "json"},
+			stdin:            `{"mls": "a", "docid": "1", "data": [{"key": "ListPrice", "value": 1}, {"key": "DontMapMe", "value": "555-555-5555"}]}`,
+			expectedCode:     exitOK,
+			expectedListings: 1,
+			expectedErrors:   []string{`"key":"DontMapMe"`, `"event":"unmapped"`},
+			expectedOutput:   []string{`"extras":[{"key":"DontMapMe","value":"555-555-5555"}]`},
+		},
+		{
+			description:      "it should reject duplicate keys of flat documents by default",
+			args:             []string{"validate", "--schema", "schema.json", "--document"},
+			stdin:            `{"mls": "a", "docid": "1", "data": [{"key": "ListPrice", "value": 1}, {"key": "ListPrice", "value": 2}]}`,
+			expectedCode:     exitInvalid,
+			expectedListings: 1,
+			expectedErrors:   []string{"duplicate key"},
+		},
+		{
+			description:  "it should fail on an unknown unmapped policy",
+			args:         []string{"validate", "--unmapped", "ignore"},
+			expectedCode: exitError,
+		},
+		{
+			description:  "it should fail on an unknown format",
+			args:         []string{"validate", "--format", "xml"},
+			expectedCode: exitError,
+		},
+		{
+			description:  "it should fail on a missing schema",
+			args:         []string{"validate", "--schema", "testdata/missing.json"},
+			expectedCode: exitError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var stdout, stderr bytes.Buffer
+		code := run(testCase.args, strings.NewReader(testCase.stdin), &stdout, &stderr)
+		if code != testCase.expectedCode {
+			t.Fatalf("%s: expected exit code %d, got %d, stderr: %s", testCase.description, testCase.expectedCode, code, stderr.String())
+		}
+		if listings := strings.Count(stdout.String(), "\n"); listings != testCase.expectedListings {
+			t.Fatalf("%s: expected %d listings, got %d: %s", testCase.description, testCase.expectedListings, listings, stdout.String())
+		}
+		for _, e := range testCase.expectedErrors {
+			if !strings.Contains(stderr.String(), e) {
+				t.Fatalf("%s: expected error output to contain <%s>, got: %s", testCase.description, e, stderr.String())
+			}
+		}
+		for _, o := range testCase.expectedOutput {
+			if !strings.Contains(stdout.String(), o) {
+				t.Fatalf("%s: expected output to contain <%s>, got: %s", testCase.description, o, stdout.String())
+			}
+		}
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestValidateCommandReportWriteFailure(t *testing.T) {
+	var stdout bytes.Buffer
+	stdin := `{"mls": "a", "docid": "1", "data": [{"key": "ListPrice", "value": "x"}]}`
+	code := run([]string{"validate", "--schema", "schema.json"}, strings.NewReader(stdin), &stdout, failingWriter{})
+	if code != exitError {
+		t.Fatalf("expected a failed report write to exit with %d, got %d", exitError, code)
+	}
+}
+
+func TestResolveCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"resolve", "--schema", "schema.json", "--overlays", "testdata/overlays", "--mls", "rets-properties-test"}, strings.NewReader(""), &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d, stderr: %s", exitOK, code, stderr.String())
+	}
+	for _, expected := range []string{`"DontMapMe"`, `"minimum": 0`} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Fatalf("expected effective schema to contain <%s>, got: %s", expected, stdout.String())
+		}
+	}
+	for _, unexpected := range []string{`"NumArray"`, `"maximum"`} {
+		if strings.Contains(stdout.String(), unexpected) {
+			t.Fatalf("expected effective schema not to contain <%s>, got: %s", unexpected, stdout.String())
+		}
+	}
+}
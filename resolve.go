@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+)
+
+func runResolve(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("resolve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: jsonschema resolve [flags]")
+		fmt.Fprintln(stderr, "\nprints the effective schema of an mls, the base schema merged with its overlay")
+		fs.PrintDefaults()
+	}
+	schemaPath := fs.String("schema", "./schema.json", "path to the base schema file")
+	overlaysDir := fs.String("overlays", "", "directory of <mls>.json overlays")
+	mls := fs.String("mls", "", "mls to resolve the schema of")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitError
+	}
+
+	resolver, err := loadResolver(*schemaPath, *overlaysDir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	s, err := resolver.For(*mls)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "    ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	return exitOK
+}
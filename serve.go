@@ -0,0 +1,69 @@
+package main
+
+import (
+	"cmenke/go-playground/lib/approach_3/server"
+	"cmenke/go-playground/lib/approach_3/store"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func runServe(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: jsonschema serve [flags]")
+		fmt.Fprintln(stderr, "\nserves validation and schemas over http, reloading the schema files when they change")
+		fs.PrintDefaults()
+	}
+	addr := fs.String("addr", ":8080", "address to listen on")
+	schemaPath := fs.String("schema", "./schema.json", "path to the base schema file")
+	overlaysDir := fs.String("overlays", "", "directory of <mls>.json overlays, uploaded overlays are written there")
+	coerce := fs.Bool("coerce", false, "coerce stringified values unless a request sets ?coerce")
+	reload := fs.Duration("reload", 5*time.Second, "how often to poll the schema files for changes")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitError
+	}
+
+	st, err := store.New(*schemaPath, *overlaysDir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go st.Watch(ctx, *reload, func(v *store.Version, err error) {
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return
+		}
+		fmt.Fprintf(stderr, "loaded schema version <%s>\n", v.ID)
+	})
+
+	srv := &http.Server{Addr: *addr, Handler: server.New(st, *coerce)}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(stderr, "serving schema version <%s> on <%s>\n", st.Current().ID, *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	return exitOK
+}
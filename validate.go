@@ -0,0 +1,171 @@
+package main
+
+import (
+	"cmenke/go-playground/lib/approach_3"
+	"cmenke/go-playground/lib/approach_3/report"
+	"cmenke/go-playground/lib/approach_3/schema"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func runValidate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: jsonschema validate [flags] [file|glob|-]...")
+		fmt.Fprintln(stderr, "\nvalidates ndjson listings, writing the cleaned listings and the error reports to separate outputs")
+		fs.PrintDefaults()
+	}
+	schemaPath := fs.String("schema", "./schema.json", "path to the schema file")
+	overlaysDir := fs.String("overlays", "", "directory of <mls>.json overlays merged into the schema by listing mls")
+	coerce := fs.Bool("coerce", false, "coerce stringified values into their schema type")
+	unmapped := fs.String("unmapped", "", "policy for keys without a schema mapping, overriding x-unmapped of the schema: accept, drop, quarantine or reject")
+	document := fs.Bool("document", false, "validate each listing as one flat document so object keywords apply across its keys")
+	duplicates := fs.String("duplicates", string(approach_3.DuplicateReject), "with --document, policy for keys appearing more than once: reject, first, last or collect")
+	format := fs.String("format", "text", "error report format, json or text")
+	failFast := fs.Bool("fail-fast", false, "stop at the first listing with a rejected key:value")
+	outPath := fs.String("out", "-", "where to write cleaned listings as ndjson, - for stdout")
+	errorsPath := fs.String("errors", "-", "where to write error reports, - for stderr")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	var newReporter func(w io.Writer) func(e report.BadKeyVal) error
+	switch *format {
+	case "json":
+		newReporter = report.NewJSONReporter
+	case "text":
+		newReporter = report.NewTextReporter
+	default:
+		fmt.Fprintf(stderr, "unknown format <%s>, expected json or text\n", *format)
+		return exitError
+	}
+	switch *unmapped {
+	case "", schema.UnmappedAccept, schema.UnmappedDrop, schema.UnmappedQuarantine, schema.UnmappedReject:
+	default:
+		fmt.Fprintf(stderr, "unknown unmapped policy <%s>, expected accept, drop, quarantine or reject\n", *unmapped)
+		return exitError
+	}
+	switch approach_3.DuplicatePolicy(*duplicates) {
+	case approach_3.DuplicateReject, approach_3.DuplicateFirst, approach_3.DuplicateLast, approach_3.DuplicateCollect:
+	default:
+		fmt.Fprintf(stderr, "unknown duplicate policy <%s>, expected reject, first, last or collect\n", *duplicates)
+		return exitError
+	}
+	opts := listingOptions{
+		coerce:     *coerce,
+		unmapped:   *unmapped,
+		document:   *document,
+		duplicates: approach_3.DuplicatePolicy(*duplicates),
+	}
+
+	resolver, err := loadResolver(*schemaPath, *overlaysDir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	paths, err := expandInputs(fs.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	out, closeOut, err := openOutput(*outPath, stdout)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	defer closeOut()
+
+	errOut, closeErrOut, err := openOutput(*errorsPath, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	defer closeErrOut()
+
+	// a report that cannot be written fails the run rather than the
+	// listing, it is kept to be told apart from validation errors
+	write := newReporter(errOut)
+	var writeErr error
+	reporter := approach_3.ReporterFunc(func(e report.BadKeyVal) error {
+		if err := write(e); err != nil {
+			writeErr = err
+			return err
+		}
+		return nil
+	})
+	enc := json.NewEncoder(out)
+	invalid := false
+	err = forEachListing(paths, stdin, func(l approach_3.Listing) error {
+		// without the schema of its mls the listing cannot be validated,
+		// which fails the run rather than the listing
+		s, err := resolver.For(l.Mls)
+		if err != nil {
+			return fmt.Errorf("failed to resolve schema of listing <%s>: %w", l.DocId, err)
+		}
+		validateErr := validateListing(s, &l, reporter, opts)
+		if writeErr != nil {
+			return fmt.Errorf("failed to write error report: %w", writeErr)
+		}
+		// a rejected listing has been reported and is not passed on
+		if !l.Rejected {
+			if err := enc.Encode(l); err != nil {
+				return err
+			}
+		}
+		if validateErr != nil {
+			invalid = true
+			if *failFast {
+				return errStop
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStop) {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	if invalid {
+		return exitInvalid
+	}
+	return exitOK
+}
+
+// listingOptions are the validate flags deciding how a listing is validated
+type listingOptions struct {
+	coerce     bool
+	unmapped   string
+	document   bool
+	duplicates approach_3.DuplicatePolicy
+}
+
+// validateListing validates l against s, the effective schema of its mls,
+// using the unmapped policy instead of the schema's when set
+func validateListing(s *schema.Schema, l *approach_3.Listing, r approach_3.Reporter, opts listingOptions) error {
+	if opts.unmapped != "" {
+		s = s.WithUnmapped(opts.unmapped)
+	}
+	if opts.document {
+		return l.ValidateDocument(s, r, opts.coerce, opts.duplicates)
+	}
+	return l.Validate(s, r, opts.coerce)
+}
+
+// openOutput opens path for writing, falling back to def for "-"
+func openOutput(path string, def io.Writer) (io.Writer, func() error, error) {
+	if path == "-" {
+		return def, func() error { return nil }, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create output <%s>: %w", path, err)
+	}
+	return f, f.Close, nil
+}